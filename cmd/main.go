@@ -2,13 +2,21 @@ package main
 
 import (
 	"fmt"
+	persistence_cache "go-boilerplate/internal/infrastructure/persistence/cache"
 	persistence_gorm "go-boilerplate/internal/infrastructure/persistence/gorm"
 	"go-boilerplate/internal/interfaces/handler"
+	"go-boilerplate/internal/pkg/auth"
 	"go-boilerplate/internal/pkg/database"
+	"go-boilerplate/internal/pkg/mailer"
+	"go-boilerplate/internal/pkg/oidc"
 	"go-boilerplate/internal/usecase"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
+	// embedded zone data lets user timezones resolve even in images without tzdata
+	_ "time/tzdata"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -24,22 +32,137 @@ func main() {
 
 	r := mux.NewRouter()
 	userRepository := persistence_gorm.NewUserRepository(db)
+	if ttl := getEnvDuration("USER_CACHE_TTL", 0); ttl > 0 {
+		userRepository = persistence_cache.NewUserRepository(userRepository, ttl)
+	}
 	todoRepository := persistence_gorm.NewTodoRepository(db)
-	authUsecase := usecase.NewAuthUseCase(userRepository)
-	userUsecase := usecase.NewUserUseCase(userRepository)
+	tagRepository := persistence_gorm.NewTagRepository(db)
+	refreshTokenRepository := persistence_gorm.NewRefreshTokenRepository(db)
+	revokedTokenRepository := persistence_cache.NewRevokedTokenRepository(
+		persistence_gorm.NewRevokedTokenRepository(db),
+		30*time.Second,
+	)
+	sessionRepository := persistence_gorm.NewSessionRepository(db)
+	userTokenRepository := persistence_gorm.NewUserTokenRepository(db)
+	loginThrottleRepository := persistence_gorm.NewLoginThrottleRepository(db)
+	lockoutEventRepository := persistence_gorm.NewLockoutEventRepository(db)
+	mfaRepository := persistence_gorm.NewMFARepository(db)
+	apiKeyRepository := persistence_gorm.NewAPIKeyRepository(db)
+	userIdentityRepository := persistence_gorm.NewUserIdentityRepository(db)
+	auditEventRepository := persistence_gorm.NewAuditEventRepository(db)
+	auditLogger := usecase.NewAuditLogger(auditEventRepository)
+	mailSender := mailer.NewMailerFromEnv()
+	keyManager, err := auth.NewKeyManagerFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading jwt keys: %v", err)
+		return
+	}
+	oidcProviders, err := oidc.NewProvidersFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading oidc providers: %v", err)
+		return
+	}
+	passwordHasher, err := auth.NewPasswordHasherFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading password hasher: %v", err)
+		return
+	}
+	passwordPolicy, err := auth.NewPasswordPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading password policy: %v", err)
+		return
+	}
+	authUsecase := usecase.NewAuthUseCase(
+		userRepository,
+		refreshTokenRepository,
+		revokedTokenRepository,
+		sessionRepository,
+		userTokenRepository,
+		loginThrottleRepository,
+		lockoutEventRepository,
+		mfaRepository,
+		apiKeyRepository,
+		userIdentityRepository,
+		oidcProviders,
+		keyManager,
+		passwordHasher,
+		passwordPolicy,
+		auditLogger,
+		mailSender,
+		usecase.LoginThrottleConfig{
+			AccountThreshold: getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+			IPThreshold:      getEnvInt("LOGIN_IP_LOCKOUT_THRESHOLD", 20),
+			BaseLockDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", time.Minute),
+			MaxLockDuration:  getEnvDuration("LOGIN_LOCKOUT_MAX_DURATION", time.Hour),
+			FailureWindow:    getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		},
+	)
+	adminUsecase := usecase.NewAdminUseCase(
+		userRepository,
+		refreshTokenRepository,
+		revokedTokenRepository,
+		sessionRepository,
+		userTokenRepository,
+		loginThrottleRepository,
+		lockoutEventRepository,
+		auditEventRepository,
+		keyManager,
+		passwordHasher,
+		auditLogger,
+		mailSender,
+	)
+	userUsecase := usecase.NewUserUseCase(
+		userRepository,
+		refreshTokenRepository,
+		revokedTokenRepository,
+		userTokenRepository,
+		passwordHasher,
+		passwordPolicy,
+		auditLogger,
+		mailSender,
+	)
+	mfaUsecase := usecase.NewMFAUseCase(userRepository, mfaRepository, auditLogger, getEnv("MFA_ISSUER", "go-boilerplate"))
+	apiKeyUsecase := usecase.NewAPIKeyUseCase(userRepository, apiKeyRepository, auditLogger)
+	sessionUsecase := usecase.NewSessionUseCase(sessionRepository, refreshTokenRepository, revokedTokenRepository, auditLogger)
 	todoUsecase := usecase.NewTodoUseCase(todoRepository)
-	authHandler := handler.NewAuthHandler(authUsecase)
-	todoHandler := handler.NewTodoHandler(todoUsecase, userUsecase)
+	tagUsecase := usecase.NewTagUseCase(tagRepository)
+	baseHandler := handler.NewBaseHandler(authUsecase, handler.BaseHandlerConfig{
+		RequireVerifiedEmail: os.Getenv("REQUIRE_EMAIL_VERIFICATION") == "true",
+		TrustProxyHeaders:    os.Getenv("TRUST_PROXY_HEADERS") == "true",
+		TrustedProxyHops:     getEnvInt("TRUSTED_PROXY_HOPS", 1),
+		SecureCookies:        os.Getenv("COOKIE_SECURE") != "false",
+		CookieSessions:       os.Getenv("SESSION_COOKIE_MODE") == "true",
+		CookieSameSite:       getEnvSameSite("COOKIE_SAME_SITE", http.SameSiteLaxMode),
+	})
+	authHandler := handler.NewAuthHandler(baseHandler, authUsecase)
+	todoHandler := handler.NewTodoHandler(baseHandler, todoUsecase)
+	tagHandler := handler.NewTagHandler(baseHandler, tagUsecase)
+	adminHandler := handler.NewAdminHandler(baseHandler, adminUsecase)
+	userHandler := handler.NewUserHandler(baseHandler, userUsecase)
+	mfaHandler := handler.NewMFAHandler(baseHandler, mfaUsecase)
+	apiKeyHandler := handler.NewAPIKeyHandler(baseHandler, apiKeyUsecase)
+	sessionHandler := handler.NewSessionHandler(baseHandler, sessionUsecase)
 
 	authHandler.RegisterAuthHandlers(r)
 	todoHandler.RegisterTodoHandlers(r)
+	tagHandler.RegisterTagHandlers(r)
+	adminHandler.RegisterAdminHandlers(r)
+	userHandler.RegisterUserHandlers(r)
+	mfaHandler.RegisterMFAHandlers(r)
+	apiKeyHandler.RegisterAPIKeyHandlers(r)
+	sessionHandler.RegisterSessionHandlers(r)
 
-	c := cors.New(cors.Options{
+	corsOptions := cors.Options{
 		// AllowedOrigins:   []string{os.Getenv("FRONTEND_URL")}, // フロントエンドのオリジン
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-CSRF-Token"},
 		AllowCredentials: true,
-	})
+	}
+	// credentialed requests carry the session cookie, so only the frontend origin may make them
+	if os.Getenv("SESSION_COOKIE_MODE") == "true" {
+		corsOptions.AllowedOrigins = []string{os.Getenv("FRONTEND_URL")}
+	}
+	c := cors.New(corsOptions)
 
 	handler := c.Handler(r)
 
@@ -48,3 +171,38 @@ func main() {
 	}
 
 }
+
+func getEnv(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvSameSite(key string, defaultValue http.SameSite) http.SameSite {
+	switch os.Getenv(key) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
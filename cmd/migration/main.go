@@ -25,7 +25,7 @@ func main() {
 	// UUID拡張機能を有効化
 	db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\";")
 
-	db.AutoMigrate(&domain.User{}, &domain.Todo{})
+	db.AutoMigrate(&domain.User{}, &domain.Todo{}, &domain.RefreshToken{}, &domain.RevokedToken{}, &domain.UserToken{}, &domain.LoginThrottle{}, &domain.LockoutEvent{}, &domain.MFACredential{}, &domain.MFARecoveryCode{}, &domain.APIKey{}, &domain.UserIdentity{}, &domain.Session{}, &domain.AuditEvent{}, &domain.Tag{}, &domain.TodoTag{})
 
 	log.Printf("Migration completed")
 }
@@ -22,6 +22,72 @@ func main() {
 		return
 	}
 
+	err = db.Migrator().DropTable(&domain.TodoTag{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.Tag{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.AuditEvent{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.Session{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.UserIdentity{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.APIKey{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.MFARecoveryCode{}, &domain.MFACredential{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.LockoutEvent{}, &domain.LoginThrottle{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.UserToken{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.RevokedToken{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
+	err = db.Migrator().DropTable(&domain.RefreshToken{})
+	if err != nil {
+		log.Fatalf("Error dropping tables: %v", err)
+		return
+	}
+
 	err = db.Migrator().DropTable(&domain.Todo{})
 	if err != nil {
 		log.Fatalf("Error dropping tables: %v", err)
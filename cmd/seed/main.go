@@ -6,6 +6,7 @@ import (
 	"go-boilerplate/internal/pkg/database"
 	"go-boilerplate/internal/pkg/pointer"
 	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -28,7 +29,12 @@ func main() {
 	userID1 := uuid.New()
 	userID2 := uuid.New()
 
-	pass, err := auth.HashPassword("password")
+	passwordHasher, err := auth.NewPasswordHasherFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading password hasher: %v", err)
+		return
+	}
+	pass, err := passwordHasher.Hash("password")
 	if err != nil {
 		log.Fatalf("Error hash password: %v", err)
 		return
@@ -36,16 +42,20 @@ func main() {
 
 	insertUserList := []*domain.User{
 		{
-			ID:       userID1,
-			Name:     "user1",
-			Email:    "user1@test.com",
-			Password: pass,
+			ID:              userID1,
+			Name:            "user1",
+			Email:           "user1@test.com",
+			Password:        pass,
+			Role:            domain.RoleAdmin,
+			EmailVerifiedAt: pointer.Time(time.Now()),
 		},
 		{
-			ID:       userID2,
-			Name:     "user2",
-			Email:    "user2@test.com",
-			Password: pass,
+			ID:              userID2,
+			Name:            "user2",
+			Email:           "user2@test.com",
+			Password:        pass,
+			Role:            domain.RoleUser,
+			EmailVerifiedAt: pointer.Time(time.Now()),
 		},
 	}
 
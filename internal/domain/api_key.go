@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey はスクリプトや外部連携向けの個人用アクセストークンです。
+// 平文は作成時に一度だけ返し、識別用のプレフィックスとハッシュのみを保存します
+type APIKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"type:varchar(100);not null"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(20);not null"`
+	KeyHash    string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Scopes     string     `json:"scopes" gorm:"type:text;not null"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// JoinScopes はスコープを OAuth と同じくスペース区切りで保存するための文字列にします
+func JoinScopes(scopes []Permission) string {
+	values := make([]string, len(scopes))
+	for i, scope := range scopes {
+		values[i] = string(scope)
+	}
+	return strings.Join(values, " ")
+}
+
+func SplitScopes(scopes string) []Permission {
+	fields := strings.Fields(scopes)
+	permissions := make([]Permission, len(fields))
+	for i, field := range fields {
+		permissions[i] = Permission(field)
+	}
+	return permissions
+}
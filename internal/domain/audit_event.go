@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditActionSignup             AuditAction = "auth.signup"
+	AuditActionLogin              AuditAction = "auth.login"
+	AuditActionTokenRefresh       AuditAction = "auth.token_refresh"
+	AuditActionLogout             AuditAction = "auth.logout"
+	AuditActionLogoutAll          AuditAction = "auth.logout_all"
+	AuditActionPasswordReset      AuditAction = "auth.password_reset"
+	AuditActionMagicLinkRequest   AuditAction = "auth.magic_link_request"
+	AuditActionPasswordChange     AuditAction = "account.password_change"
+	AuditActionAccountDelete      AuditAction = "account.delete"
+	AuditActionEmailChange        AuditAction = "account.email_change"
+	AuditActionMFAEnroll          AuditAction = "account.mfa_enroll"
+	AuditActionMFAEnable          AuditAction = "account.mfa_enable"
+	AuditActionMFADisable         AuditAction = "account.mfa_disable"
+	AuditActionAPIKeyCreate       AuditAction = "account.api_key_create"
+	AuditActionAPIKeyRevoke       AuditAction = "account.api_key_revoke"
+	AuditActionSessionRevoke      AuditAction = "account.session_revoke"
+	AuditActionUserDisable        AuditAction = "admin.user_disable"
+	AuditActionUserEnable         AuditAction = "admin.user_enable"
+	AuditActionUserDelete         AuditAction = "admin.user_delete"
+	AuditActionUserPasswordReset  AuditAction = "admin.user_password_reset"
+	AuditActionLockoutClear       AuditAction = "admin.lockout_clear"
+	AuditActionImpersonationStart AuditAction = "impersonation.start"
+	AuditActionImpersonationStop  AuditAction = "impersonation.stop"
+)
+
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionSignup, AuditActionLogin, AuditActionTokenRefresh,
+		AuditActionLogout, AuditActionLogoutAll, AuditActionPasswordReset, AuditActionPasswordChange,
+		AuditActionAccountDelete, AuditActionUserDisable, AuditActionUserEnable, AuditActionUserDelete,
+		AuditActionUserPasswordReset, AuditActionImpersonationStart, AuditActionImpersonationStop,
+		AuditActionMagicLinkRequest, AuditActionEmailChange, AuditActionMFAEnroll, AuditActionMFAEnable,
+		AuditActionMFADisable, AuditActionAPIKeyCreate, AuditActionAPIKeyRevoke, AuditActionSessionRevoke,
+		AuditActionLockoutClear:
+		return true
+	}
+	return false
+}
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+func (o AuditOutcome) IsValid() bool {
+	return o == AuditOutcomeSuccess || o == AuditOutcomeFailure
+}
+
+// AuditEvent は認証やアカウント操作の監査記録です。追記のみで、更新・削除はしません。
+// ユーザーが削除されても記録を残すため、ActorID と TargetID には外部キーを張りません
+type AuditEvent struct {
+	ID        uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Action    AuditAction  `json:"action" gorm:"type:varchar(64);not null;index"`
+	Outcome   AuditOutcome `json:"outcome" gorm:"type:varchar(20);not null"`
+	ActorID   *uuid.UUID   `json:"actor_id" gorm:"type:uuid;index"`
+	TargetID  *uuid.UUID   `json:"target_id" gorm:"type:uuid;index"`
+	IPAddress string       `json:"ip_address" gorm:"type:varchar(45)"`
+	UserAgent string       `json:"user_agent" gorm:"type:varchar(512)"`
+	Metadata  string       `json:"metadata" gorm:"type:text"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
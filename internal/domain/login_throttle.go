@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoginThrottleScope string
+
+const (
+	LoginThrottleScopeAccount LoginThrottleScope = "account"
+	LoginThrottleScopeIP      LoginThrottleScope = "ip"
+)
+
+// LoginThrottle はアカウントまたは IP アドレスごとのログイン失敗回数とロック状態です
+type LoginThrottle struct {
+	ID             uuid.UUID          `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Scope          LoginThrottleScope `json:"scope" gorm:"type:varchar(20);not null;uniqueIndex:idx_login_throttles_scope_identifier"`
+	Identifier     string             `json:"identifier" gorm:"type:varchar(255);not null;uniqueIndex:idx_login_throttles_scope_identifier"`
+	FailedAttempts int                `json:"failed_attempts" gorm:"not null;default:0"`
+	LastFailedAt   time.Time          `json:"last_failed_at" gorm:"not null"`
+	LockedUntil    *time.Time         `json:"locked_until"`
+}
+
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
+
+// LockoutEvent はロックが発生した記録です。管理者が確認・解除できるように残します
+type LockoutEvent struct {
+	ID             uuid.UUID          `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Scope          LoginThrottleScope `json:"scope" gorm:"type:varchar(20);not null;index:idx_lockout_events_scope_identifier"`
+	Identifier     string             `json:"identifier" gorm:"type:varchar(255);not null;index:idx_lockout_events_scope_identifier"`
+	FailedAttempts int                `json:"failed_attempts" gorm:"not null"`
+	LockedUntil    time.Time          `json:"locked_until" gorm:"not null"`
+	ClearedAt      *time.Time         `json:"cleared_at"`
+	CreatedAt      time.Time          `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (LockoutEvent) TableName() string {
+	return "lockout_events"
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokedToken は失効させたアクセストークン(jti)またはセッション(sid)を表します
+type RevokedToken struct {
+	TokenID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"token_id"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
@@ -0,0 +1,68 @@
+package domain
+
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermissionTodosRead  Permission = "todos:read"
+	PermissionTodosWrite Permission = "todos:write"
+	PermissionUsersRead  Permission = "users:read"
+	PermissionUsersWrite Permission = "users:write"
+	// PermissionUsersImpersonate はサポートのために他のユーザーとしてログインする権限です
+	PermissionUsersImpersonate Permission = "users:impersonate"
+)
+
+var permissions = []Permission{
+	PermissionTodosRead,
+	PermissionTodosWrite,
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersImpersonate,
+}
+
+var rolePermissions = map[Role][]Permission{
+	RoleUser: {
+		PermissionTodosRead,
+		PermissionTodosWrite,
+	},
+	RoleAdmin: {
+		PermissionTodosRead,
+		PermissionTodosWrite,
+		PermissionUsersRead,
+		PermissionUsersWrite,
+		PermissionUsersImpersonate,
+	},
+}
+
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+func (r Role) HasPermission(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Permission) IsValid() bool {
+	for _, permission := range permissions {
+		if permission == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session はログインしている端末です。ID はリフレッシュトークンのファミリー ID と同じで、
+// アクセストークンの sid クレームにも入ります。ImpersonatorID はなりすましで作られたセッションの場合に管理者の ID を持ちます
+type Session struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	UserAgent      string     `json:"user_agent" gorm:"type:varchar(512)"`
+	IPAddress      string     `json:"ip_address" gorm:"type:varchar(45)"`
+	ImpersonatorID *uuid.UUID `json:"impersonator_id" gorm:"type:uuid"`
+	LastSeenAt     time.Time  `json:"last_seen_at" gorm:"not null"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	User           User       `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag は todo をまとめるためのユーザーごとのラベルです。名前はユーザー内で一意です
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_id_name"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_user_id_name"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TodoTag は todo とタグの多対多の関連です。どちらかが削除されると関連も削除されます
+type TodoTag struct {
+	TodoID    uuid.UUID `json:"todo_id" gorm:"type:uuid;primaryKey"`
+	TagID     uuid.UUID `json:"tag_id" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	Todo      Todo      `gorm:"foreignKey:TodoID;constraint:OnDelete:CASCADE;"`
+	Tag       Tag       `gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE;"`
+}
+
+func (TodoTag) TableName() string {
+	return "todo_tags"
+}
@@ -6,16 +6,52 @@ import (
 	"github.com/google/uuid"
 )
 
+type TodoStatus string
+
+const (
+	TodoStatusOpen       TodoStatus = "open"
+	TodoStatusInProgress TodoStatus = "in_progress"
+	TodoStatusDone       TodoStatus = "done"
+	TodoStatusCancelled  TodoStatus = "cancelled"
+)
+
+func (s TodoStatus) IsValid() bool {
+	switch s {
+	case TodoStatusOpen, TodoStatusInProgress, TodoStatusDone, TodoStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// TodoPriority は todo の優先度で、値が大きいほど優先されます
+type TodoPriority int
+
+const (
+	TodoPriorityNone   TodoPriority = 0
+	TodoPriorityLow    TodoPriority = 1
+	TodoPriorityMedium TodoPriority = 2
+	TodoPriorityHigh   TodoPriority = 3
+)
+
+func (p TodoPriority) IsValid() bool {
+	return p >= TodoPriorityNone && p <= TodoPriorityHigh
+}
+
+// Todo の CompletedAt は Status が done の間だけ設定されます。DueAt は期限の日時で、未設定の場合は nil です
 type Todo struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	UserID    uuid.UUID `json:"user_id" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
-	Title     string    `json:"title"`
-	Content   *string   `json:"content"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-	User      User      `gorm:"foreignKey:UserID"`
+	ID          uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID      uuid.UUID    `json:"user_id" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Title       string       `json:"title"`
+	Content     *string      `json:"content"`
+	Status      TodoStatus   `json:"status" gorm:"type:varchar(20);not null;default:'open';index"`
+	CompletedAt *time.Time   `json:"completed_at"`
+	DueAt       *time.Time   `json:"due_at" gorm:"index"`
+	Priority    TodoPriority `json:"priority" gorm:"type:smallint;not null;default:0"`
+	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+	User        User         `gorm:"foreignKey:UserID"`
 }
 
 func (Todo) TableName() string {
 	return "todos"
-}
\ No newline at end of file
+}
@@ -7,14 +7,18 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
-	Name      string     `json:"name"`
-	Email     string     `json:"email" gorm:"type:varchar(100);unique;not null"`
-	Password  string     `json:"password"`
-	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
-	Todos     []Todo     `gorm:"foreignKey:UserID"`
+	ID              uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email" gorm:"type:varchar(100);not null;uniqueIndex:idx_users_email,where:deleted_at IS NULL"`
+	Password        string     `json:"password"`
+	Role            Role       `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
+	Timezone        string     `json:"timezone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	DisabledAt      *time.Time `json:"disabled_at"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt       *time.Time `json:"deleted_at" gorm:"index"`
+	Todos           []Todo     `gorm:"foreignKey:UserID"`
 }
 
 func (User) TableName() string {
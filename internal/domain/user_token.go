@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserTokenPurpose string
+
+const (
+	UserTokenPurposePasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenPurposeEmailVerification UserTokenPurpose = "email_verification"
+	UserTokenPurposeMagicLink         UserTokenPurpose = "magic_link"
+)
+
+// UserToken はメールで送る使い捨てトークンです。平文は保存せずハッシュのみを保持します
+type UserToken struct {
+	ID        uuid.UUID        `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserID    uuid.UUID        `json:"user_id" gorm:"type:uuid;not null;index"`
+	Purpose   UserTokenPurpose `json:"purpose" gorm:"type:varchar(50);not null"`
+	TokenHash string           `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time        `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time       `json:"used_at"`
+	CreatedAt time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	User      User             `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
@@ -0,0 +1,57 @@
+package persistence_cache
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/cache"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 失効は取り消されないため、DB から失効済みと分かった結果は長めに保持して問題ありません
+const revokedTTL = time.Hour
+
+// revokedTokenRepository は失効ストアの前段に置くインメモリキャッシュです。
+// このインスタンスで失効させたトークンは有効期限まで、DB から失効済みと分かった結果は revokedTTL の間、
+// 未失効の結果は notRevokedTTL の間だけ保持します。
+// 他のインスタンスで失効したトークンは最大 notRevokedTTL の間だけ有効とみなされます。
+type revokedTokenRepository struct {
+	next          repository.RevokedTokenRepository
+	cache         *cache.TTLCache[uuid.UUID, bool]
+	notRevokedTTL time.Duration
+}
+
+func NewRevokedTokenRepository(next repository.RevokedTokenRepository, notRevokedTTL time.Duration) repository.RevokedTokenRepository {
+	return &revokedTokenRepository{
+		next:          next,
+		cache:         cache.NewTTLCache[uuid.UUID, bool](),
+		notRevokedTTL: notRevokedTTL,
+	}
+}
+
+func (r *revokedTokenRepository) Create(ctx context.Context, input *dto.CreateRevokedTokenInput) error {
+	if err := r.next.Create(ctx, input); err != nil {
+		return err
+	}
+	r.cache.Set(input.TokenID, true, time.Until(input.ExpiresAt))
+	return nil
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, input *dto.IsTokenRevokedInput) (bool, error) {
+	if revoked, ok := r.cache.Get(input.TokenID); ok {
+		return revoked, nil
+	}
+
+	revoked, err := r.next.IsRevoked(ctx, input)
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		r.cache.Set(input.TokenID, true, revokedTTL)
+	} else {
+		r.cache.Set(input.TokenID, false, r.notRevokedTTL)
+	}
+	return revoked, nil
+}
@@ -0,0 +1,82 @@
+package persistence_cache
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/cache"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// userRepository はリクエストごとのユーザー読み込みを軽くするため、FindByID の結果を ttl の間だけ保持します。
+// このインスタンス経由の更新ではキャッシュを破棄しますが、他のインスタンスでの更新は最大 ttl の間反映されません。
+type userRepository struct {
+	next  repository.UserRepository
+	cache *cache.TTLCache[uuid.UUID, dto.UserOutput]
+	ttl   time.Duration
+}
+
+func NewUserRepository(next repository.UserRepository, ttl time.Duration) repository.UserRepository {
+	return &userRepository{
+		next:  next,
+		cache: cache.NewTTLCache[uuid.UUID, dto.UserOutput](),
+		ttl:   ttl,
+	}
+}
+
+func (r *userRepository) FindByID(ctx context.Context, input *dto.FindUserByIDInput) (*dto.UserOutput, error) {
+	if user, ok := r.cache.Get(input.ID); ok {
+		return &user, nil
+	}
+
+	user, err := r.next.FindByID(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(input.ID, *user, r.ttl)
+	return user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, input *dto.FindUserByEmailInput) (*dto.UserOutput, error) {
+	return r.next.FindByEmail(ctx, input)
+}
+
+func (r *userRepository) Create(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error) {
+	return r.next.Create(ctx, input)
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, input *dto.UpdateUserPasswordInput) error {
+	defer r.cache.Delete(input.ID)
+	return r.next.UpdatePassword(ctx, input)
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, input *dto.MarkUserEmailVerifiedInput) error {
+	defer r.cache.Delete(input.ID)
+	return r.next.MarkEmailVerified(ctx, input)
+}
+
+func (r *userRepository) List(ctx context.Context, input *dto.ListUsersInput) (*dto.UserListOutput, error) {
+	return r.next.List(ctx, input)
+}
+
+func (r *userRepository) SetDisabled(ctx context.Context, input *dto.SetUserDisabledInput) error {
+	defer r.cache.Delete(input.ID)
+	return r.next.SetDisabled(ctx, input)
+}
+
+func (r *userRepository) Update(ctx context.Context, input *dto.UpdateUserInput) (*dto.UserOutput, error) {
+	defer r.cache.Delete(input.ID)
+	return r.next.Update(ctx, input)
+}
+
+func (r *userRepository) SoftDelete(ctx context.Context, input *dto.DeleteUserInput) error {
+	defer r.cache.Delete(input.ID)
+	return r.next.SoftDelete(ctx, input)
+}
+
+func (r *userRepository) Delete(ctx context.Context, input *dto.DeleteUserInput) error {
+	defer r.cache.Delete(input.ID)
+	return r.next.Delete(ctx, input)
+}
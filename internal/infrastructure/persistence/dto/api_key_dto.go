@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAPIKeyInput struct {
+	UserID    uuid.UUID           `json:"user_id" validate:"required"`
+	Name      string              `json:"name" validate:"required"`
+	Prefix    string              `json:"prefix" validate:"required"`
+	KeyHash   string              `json:"key_hash" validate:"required"`
+	Scopes    []domain.Permission `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time          `json:"expires_at"`
+}
+
+type ListAPIKeysInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type FindAPIKeyByHashInput struct {
+	KeyHash string `json:"key_hash" validate:"required"`
+}
+
+type RevokeAPIKeyInput struct {
+	ID     uuid.UUID `json:"id" validate:"required"`
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type TouchAPIKeyInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+	// Interval より前に記録された場合だけ last_used_at を更新し、リクエストごとの書き込みを避けます
+	Interval time.Duration `json:"interval"`
+}
+
+type APIKeyOutput struct {
+	ID         uuid.UUID           `json:"id"`
+	UserID     uuid.UUID           `json:"user_id"`
+	Name       string              `json:"name"`
+	Prefix     string              `json:"prefix"`
+	Scopes     []domain.Permission `json:"scopes"`
+	ExpiresAt  *time.Time          `json:"expires_at"`
+	LastUsedAt *time.Time          `json:"last_used_at"`
+	RevokedAt  *time.Time          `json:"revoked_at"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+func ConvertAPIKeyOutput(key *domain.APIKey) *APIKeyOutput {
+	return &APIKeyOutput{
+		ID:         key.ID,
+		UserID:     key.UserID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     domain.SplitScopes(key.Scopes),
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
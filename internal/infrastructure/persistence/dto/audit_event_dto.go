@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"encoding/json"
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAuditEventInput struct {
+	Action    domain.AuditAction  `json:"action" validate:"required"`
+	Outcome   domain.AuditOutcome `json:"outcome" validate:"required"`
+	ActorID   *uuid.UUID          `json:"actor_id"`
+	TargetID  *uuid.UUID          `json:"target_id"`
+	IPAddress string              `json:"ip_address"`
+	UserAgent string              `json:"user_agent"`
+	Metadata  map[string]string   `json:"metadata"`
+}
+
+// ListAuditEventsInput の各条件は指定されたものだけで絞り込みます
+type ListAuditEventsInput struct {
+	Action   domain.AuditAction  `json:"action"`
+	Outcome  domain.AuditOutcome `json:"outcome"`
+	ActorID  *uuid.UUID          `json:"actor_id"`
+	TargetID *uuid.UUID          `json:"target_id"`
+	From     *time.Time          `json:"from"`
+	To       *time.Time          `json:"to"`
+	Limit    int                 `json:"limit" validate:"required,min=1,max=100"`
+	Offset   int                 `json:"offset" validate:"min=0"`
+}
+
+type AuditEventOutput struct {
+	ID        uuid.UUID           `json:"id"`
+	Action    domain.AuditAction  `json:"action"`
+	Outcome   domain.AuditOutcome `json:"outcome"`
+	ActorID   *uuid.UUID          `json:"actor_id"`
+	TargetID  *uuid.UUID          `json:"target_id"`
+	IPAddress string              `json:"ip_address"`
+	UserAgent string              `json:"user_agent"`
+	Metadata  map[string]string   `json:"metadata"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+func ConvertAuditEventOutput(event *domain.AuditEvent) *AuditEventOutput {
+	var metadata map[string]string
+	if event.Metadata != "" {
+		// metadata is only ever written by this application, so a broken value is simply dropped
+		_ = json.Unmarshal([]byte(event.Metadata), &metadata)
+	}
+	return &AuditEventOutput{
+		ID:        event.ID,
+		Action:    event.Action,
+		Outcome:   event.Outcome,
+		ActorID:   event.ActorID,
+		TargetID:  event.TargetID,
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Metadata:  metadata,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+type AuditEventListOutput struct {
+	Events []AuditEventOutput `json:"events"`
+	Total  int64              `json:"total"`
+}
+
+func ConvertAuditEventListOutput(events []*domain.AuditEvent, total int64) *AuditEventListOutput {
+	outputs := make([]AuditEventOutput, len(events))
+	for i, event := range events {
+		outputs[i] = *ConvertAuditEventOutput(event)
+	}
+	return &AuditEventListOutput{
+		Events: outputs,
+		Total:  total,
+	}
+}
@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FindLoginThrottleInput struct {
+	Scope      domain.LoginThrottleScope `json:"scope" validate:"required"`
+	Identifier string                    `json:"identifier" validate:"required"`
+}
+
+type RecordLoginFailureInput struct {
+	Scope      domain.LoginThrottleScope `json:"scope" validate:"required"`
+	Identifier string                    `json:"identifier" validate:"required"`
+	// 最後の失敗かロックの解除から Window が過ぎていればカウントをやり直します
+	Window time.Duration `json:"window" validate:"required"`
+}
+
+type LockLoginThrottleInput struct {
+	Scope       domain.LoginThrottleScope `json:"scope" validate:"required"`
+	Identifier  string                    `json:"identifier" validate:"required"`
+	LockedUntil time.Time                 `json:"locked_until" validate:"required"`
+}
+
+type ResetLoginThrottleInput struct {
+	Scope      domain.LoginThrottleScope `json:"scope" validate:"required"`
+	Identifier string                    `json:"identifier" validate:"required"`
+}
+
+type LoginThrottleOutput struct {
+	Scope          domain.LoginThrottleScope `json:"scope"`
+	Identifier     string                    `json:"identifier"`
+	FailedAttempts int                       `json:"failed_attempts"`
+	LastFailedAt   time.Time                 `json:"last_failed_at"`
+	LockedUntil    *time.Time                `json:"locked_until"`
+}
+
+func ConvertLoginThrottleOutput(throttle *domain.LoginThrottle) *LoginThrottleOutput {
+	return &LoginThrottleOutput{
+		Scope:          throttle.Scope,
+		Identifier:     throttle.Identifier,
+		FailedAttempts: throttle.FailedAttempts,
+		LastFailedAt:   throttle.LastFailedAt,
+		LockedUntil:    throttle.LockedUntil,
+	}
+}
+
+type CreateLockoutEventInput struct {
+	Scope          domain.LoginThrottleScope `json:"scope" validate:"required"`
+	Identifier     string                    `json:"identifier" validate:"required"`
+	FailedAttempts int                       `json:"failed_attempts" validate:"required"`
+	LockedUntil    time.Time                 `json:"locked_until" validate:"required"`
+}
+
+type ListLockoutEventsInput struct {
+	ActiveOnly bool `json:"active_only"`
+	Limit      int  `json:"limit" validate:"required,min=1,max=100"`
+	Offset     int  `json:"offset" validate:"min=0"`
+}
+
+type FindLockoutEventByIDInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type ClearLockoutEventsInput struct {
+	Scope      domain.LoginThrottleScope `json:"scope" validate:"required"`
+	Identifier string                    `json:"identifier" validate:"required"`
+}
+
+type LockoutEventOutput struct {
+	ID             uuid.UUID                 `json:"id"`
+	Scope          domain.LoginThrottleScope `json:"scope"`
+	Identifier     string                    `json:"identifier"`
+	FailedAttempts int                       `json:"failed_attempts"`
+	LockedUntil    time.Time                 `json:"locked_until"`
+	ClearedAt      *time.Time                `json:"cleared_at"`
+	CreatedAt      time.Time                 `json:"created_at"`
+}
+
+type LockoutEventListOutput struct {
+	Events []LockoutEventOutput `json:"events"`
+	Total  int64                `json:"total"`
+}
+
+func ConvertLockoutEventOutput(event *domain.LockoutEvent) *LockoutEventOutput {
+	return &LockoutEventOutput{
+		ID:             event.ID,
+		Scope:          event.Scope,
+		Identifier:     event.Identifier,
+		FailedAttempts: event.FailedAttempts,
+		LockedUntil:    event.LockedUntil,
+		ClearedAt:      event.ClearedAt,
+		CreatedAt:      event.CreatedAt,
+	}
+}
+
+func ConvertLockoutEventListOutput(events []*domain.LockoutEvent, total int64) *LockoutEventListOutput {
+	outputs := make([]LockoutEventOutput, len(events))
+	for i, event := range events {
+		outputs[i] = *ConvertLockoutEventOutput(event)
+	}
+	return &LockoutEventListOutput{
+		Events: outputs,
+		Total:  total,
+	}
+}
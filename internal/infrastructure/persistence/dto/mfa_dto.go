@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FindMFACredentialInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type SaveMFACredentialInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Secret string    `json:"secret" validate:"required"`
+}
+
+type ConfirmMFACredentialInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Step   int64     `json:"step" validate:"required"`
+}
+
+type UseMFAStepInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Step   int64     `json:"step" validate:"required"`
+}
+
+type DeleteMFACredentialInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type ReplaceMFARecoveryCodesInput struct {
+	UserID     uuid.UUID `json:"user_id" validate:"required"`
+	CodeHashes []string  `json:"code_hashes" validate:"required"`
+}
+
+type ConsumeMFARecoveryCodeInput struct {
+	UserID   uuid.UUID `json:"user_id" validate:"required"`
+	CodeHash string    `json:"code_hash" validate:"required"`
+}
+
+type MFACredentialOutput struct {
+	UserID       uuid.UUID  `json:"user_id"`
+	Secret       string     `json:"secret"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"last_used_step"`
+}
+
+func ConvertMFACredentialOutput(credential *domain.MFACredential) *MFACredentialOutput {
+	return &MFACredentialOutput{
+		UserID:       credential.UserID,
+		Secret:       credential.Secret,
+		ConfirmedAt:  credential.ConfirmedAt,
+		LastUsedStep: credential.LastUsedStep,
+	}
+}
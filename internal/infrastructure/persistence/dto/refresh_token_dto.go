@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FindRefreshTokenByHashInput struct {
+	TokenHash string `json:"token_hash" validate:"required"`
+}
+
+type CreateRefreshTokenInput struct {
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	FamilyID  uuid.UUID `json:"family_id" validate:"required"`
+	TokenHash string    `json:"token_hash" validate:"required"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+}
+
+type MarkRefreshTokenRotatedInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type RevokeRefreshTokenFamilyInput struct {
+	FamilyID uuid.UUID `json:"family_id" validate:"required"`
+}
+
+type ListActiveRefreshTokenFamiliesInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type RefreshTokenOutput struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	FamilyID  uuid.UUID  `json:"family_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func ConvertRefreshTokenOutput(token *domain.RefreshToken) *RefreshTokenOutput {
+	return &RefreshTokenOutput{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		FamilyID:  token.FamilyID,
+		ExpiresAt: token.ExpiresAt,
+		RotatedAt: token.RotatedAt,
+		RevokedAt: token.RevokedAt,
+		CreatedAt: token.CreatedAt,
+	}
+}
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateRevokedTokenInput struct {
+	TokenID   uuid.UUID `json:"token_id" validate:"required"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required"`
+}
+
+type IsTokenRevokedInput struct {
+	TokenID uuid.UUID `json:"token_id" validate:"required"`
+}
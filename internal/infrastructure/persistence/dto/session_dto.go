@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateSessionInput struct {
+	ID             uuid.UUID  `json:"id" validate:"required"`
+	UserID         uuid.UUID  `json:"user_id" validate:"required"`
+	UserAgent      string     `json:"user_agent"`
+	IPAddress      string     `json:"ip_address"`
+	ImpersonatorID *uuid.UUID `json:"impersonator_id"`
+}
+
+type ListActiveSessionsInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type FindSessionInput struct {
+	ID     uuid.UUID `json:"id" validate:"required"`
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+type TouchSessionInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+	// Interval より前に記録された場合だけ last_seen_at を更新し、リクエストごとの書き込みを避けます
+	Interval time.Duration `json:"interval"`
+}
+
+type SessionOutput struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	UserAgent      string     `json:"user_agent"`
+	IPAddress      string     `json:"ip_address"`
+	ImpersonatorID *uuid.UUID `json:"impersonator_id"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func ConvertSessionOutput(session *domain.Session) *SessionOutput {
+	return &SessionOutput{
+		ID:             session.ID,
+		UserID:         session.UserID,
+		UserAgent:      session.UserAgent,
+		IPAddress:      session.IPAddress,
+		ImpersonatorID: session.ImpersonatorID,
+		LastSeenAt:     session.LastSeenAt,
+		CreatedAt:      session.CreatedAt,
+	}
+}
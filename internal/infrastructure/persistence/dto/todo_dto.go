@@ -2,13 +2,66 @@ package dto
 
 import (
 	"go-boilerplate/internal/domain"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type TodoSortField string
+
+const (
+	TodoSortCreatedAt TodoSortField = "created_at"
+	TodoSortUpdatedAt TodoSortField = "updated_at"
+	TodoSortTitle     TodoSortField = "title"
+	TodoSortDueAt     TodoSortField = "due_at"
+	TodoSortPriority  TodoSortField = "priority"
+)
+
+func (f TodoSortField) IsValid() bool {
+	switch f {
+	case TodoSortCreatedAt, TodoSortUpdatedAt, TodoSortTitle, TodoSortDueAt, TodoSortPriority:
+		return true
+	}
+	return false
+}
+
+type TodoSort struct {
+	Field TodoSortField `json:"field"`
+	Desc  bool          `json:"desc"`
+}
+
+// FormatTodoSort は並び順を sort クエリパラメータと同じ "-updated_at,title" の形式にします
+func FormatTodoSort(sort []TodoSort) string {
+	fields := make([]string, len(sort))
+	for i, s := range sort {
+		fields[i] = string(s.Field)
+		if s.Desc {
+			fields[i] = "-" + fields[i]
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// FindAllInput の Cursor は前回の結果の NextCursor か PrevCursor で、空の場合は先頭のページを返します。
+// Query はタイトルと内容の部分一致、日時の範囲は From 以上 To 未満、Statuses はいずれかに一致するもので絞り込みます。
+// TagIDs は MatchAllTags が true の場合はすべて、false の場合はいずれかのタグが付いたものに絞り込みます。
+// Sort が空の場合は作成日時の新しい順です
 type FindAllInput struct {
-	UserID uuid.UUID `json:"user_id" validate:"required"`
+	UserID       uuid.UUID           `json:"user_id" validate:"required"`
+	Query        string              `json:"query" validate:"max=100"`
+	CreatedFrom  *time.Time          `json:"created_from"`
+	CreatedTo    *time.Time          `json:"created_to"`
+	UpdatedFrom  *time.Time          `json:"updated_from"`
+	UpdatedTo    *time.Time          `json:"updated_to"`
+	DueFrom      *time.Time          `json:"due_from"`
+	DueTo        *time.Time          `json:"due_to"`
+	Statuses     []domain.TodoStatus `json:"statuses"`
+	TagIDs       []uuid.UUID         `json:"tag_ids"`
+	MatchAllTags bool                `json:"match_all_tags"`
+	Sort         []TodoSort          `json:"sort"`
+	Cursor       string              `json:"cursor"`
+	Limit        int                 `json:"limit" validate:"required,min=1,max=100"`
 }
 
 type FindByIDInput struct {
@@ -17,16 +70,33 @@ type FindByIDInput struct {
 }
 
 type CreateTodoInput struct {
-	UserID  uuid.UUID `json:"user_id" validate:"required"`
-	Title   string    `json:"title" validate:"required,min=1,max=100"`
-	Content *string   `json:"content" validate:"omitempty,max=1000"`
+	UserID   uuid.UUID           `json:"user_id" validate:"required"`
+	Title    string              `json:"title" validate:"required,min=1,max=100"`
+	Content  *string             `json:"content" validate:"omitempty,max=1000"`
+	DueAt    *time.Time          `json:"due_at"`
+	Priority domain.TodoPriority `json:"priority" validate:"min=0,max=3"`
+	TagIDs   []uuid.UUID         `json:"tag_ids"`
 }
 
+// UpdateTodoInput の TagIDs が nil の場合はタグを変更せず、空の場合はすべて外します
 type UpdateTodoInput struct {
-	ID      uuid.UUID `json:"id" validate:"required"`
-	UserID  uuid.UUID `json:"user_id" validate:"required"`
-	Title   string    `json:"title" validate:"required,min=1,max=100"`
-	Content *string   `json:"content" validate:"omitempty,max=1000"`
+	ID       uuid.UUID           `json:"id" validate:"required"`
+	UserID   uuid.UUID           `json:"user_id" validate:"required"`
+	Title    string              `json:"title" validate:"required,min=1,max=100"`
+	Content  *string             `json:"content" validate:"omitempty,max=1000"`
+	DueAt    *time.Time          `json:"due_at"`
+	Priority domain.TodoPriority `json:"priority" validate:"min=0,max=3"`
+	TagIDs   *[]uuid.UUID        `json:"tag_ids"`
+}
+
+// UpdateTodoStatusInput の CompletedAt は done にする場合だけ指定します。
+// CurrentStatus は遷移を確認したときのステータスで、その後に変わっていれば更新しません
+type UpdateTodoStatusInput struct {
+	ID            uuid.UUID         `json:"id" validate:"required"`
+	UserID        uuid.UUID         `json:"user_id" validate:"required"`
+	CurrentStatus domain.TodoStatus `json:"current_status" validate:"required"`
+	Status        domain.TodoStatus `json:"status" validate:"required"`
+	CompletedAt   *time.Time        `json:"completed_at"`
 }
 
 type DeleteTodoInput struct {
@@ -35,27 +105,40 @@ type DeleteTodoInput struct {
 }
 
 type TodoOutput struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Title     string    `json:"title"`
-	Content   *string   `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uuid.UUID           `json:"id"`
+	UserID      uuid.UUID           `json:"user_id"`
+	Title       string              `json:"title"`
+	Content     *string             `json:"content"`
+	Status      domain.TodoStatus   `json:"status"`
+	CompletedAt *time.Time          `json:"completed_at"`
+	DueAt       *time.Time          `json:"due_at"`
+	Priority    domain.TodoPriority `json:"priority"`
+	Tags        []TagOutput         `json:"tags"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
 }
 
+// TodoListOutput の NextCursor と PrevCursor は、その方向にページがない場合は nil です
 type TodoListOutput struct {
-	Todos []TodoOutput `json:"todos"`
-	Total int64        `json:"total"`
+	Todos      []TodoOutput `json:"todos"`
+	Total      int64        `json:"total"`
+	NextCursor *string      `json:"next_cursor"`
+	PrevCursor *string      `json:"prev_cursor"`
 }
 
 func ConvertTodoOutput(todo *domain.Todo) *TodoOutput {
 	return &TodoOutput{
-		ID:        todo.ID,
-		UserID:    todo.UserID,
-		Title:     todo.Title,
-		Content:   todo.Content,
-		CreatedAt: todo.CreatedAt,
-		UpdatedAt: todo.UpdatedAt,
+		ID:          todo.ID,
+		UserID:      todo.UserID,
+		Title:       todo.Title,
+		Content:     todo.Content,
+		Status:      todo.Status,
+		CompletedAt: todo.CompletedAt,
+		DueAt:       todo.DueAt,
+		Priority:    todo.Priority,
+		Tags:        []TagOutput{},
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
 	}
 }
 
@@ -68,4 +151,4 @@ func ConvertTodoListOutput(todos []*domain.Todo, total int64) *TodoListOutput {
 		Todos: outputs,
 		Total: total,
 	}
-}
\ No newline at end of file
+}
@@ -7,33 +7,94 @@ import (
 	"github.com/google/uuid"
 )
 
+type FindUserByIDInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
 
 type FindUserByEmailInput struct {
 	Email string `json:"email" validate:"required,email"`
 }
 
 type CreateUserInput struct {
-	Name     string `json:"name" validate:"required,min=1,max=100"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=100"`
+	Name     string      `json:"name" validate:"required,min=1,max=100"`
+	Email    string      `json:"email" validate:"required,email"`
+	Password string      `json:"password" validate:"required,min=8,max=100"`
+	Role     domain.Role `json:"role" validate:"required"`
+}
+
+type UpdateUserInput struct {
+	ID              uuid.UUID  `json:"id" validate:"required"`
+	Name            string     `json:"name" validate:"required,min=1,max=100"`
+	Email           string     `json:"email" validate:"required,email"`
+	Timezone        string     `json:"timezone" validate:"required"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+type UpdateUserPasswordInput struct {
+	ID       uuid.UUID `json:"id" validate:"required"`
+	Password string    `json:"password" validate:"required"`
+}
+
+type MarkUserEmailVerifiedInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type ListUsersInput struct {
+	Query  string `json:"query"`
+	Limit  int    `json:"limit" validate:"required,min=1,max=100"`
+	Offset int    `json:"offset" validate:"min=0"`
+}
+
+type SetUserDisabledInput struct {
+	ID       uuid.UUID `json:"id" validate:"required"`
+	Disabled bool      `json:"disabled"`
+}
+
+type DeleteUserInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
 }
 
 type UserOutput struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uuid.UUID   `json:"id"`
+	Name            string      `json:"name"`
+	Email           string      `json:"email"`
+	Password        string      `json:"password"`
+	Role            domain.Role `json:"role"`
+	Timezone        string      `json:"timezone"`
+	EmailVerifiedAt *time.Time  `json:"email_verified_at"`
+	DisabledAt      *time.Time  `json:"disabled_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+type UserListOutput struct {
+	Users []UserOutput `json:"users"`
+	Total int64        `json:"total"`
 }
 
 func ConvertUserOutput(user *domain.User) *UserOutput {
 	return &UserOutput{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Password:  user.Password,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Password:        user.Password,
+		Role:            user.Role,
+		Timezone:        user.Timezone,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		DisabledAt:      user.DisabledAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+func ConvertUserListOutput(users []*domain.User, total int64) *UserListOutput {
+	outputs := make([]UserOutput, len(users))
+	for i, user := range users {
+		outputs[i] = *ConvertUserOutput(user)
+	}
+	return &UserListOutput{
+		Users: outputs,
+		Total: total,
 	}
-}
\ No newline at end of file
+}
+
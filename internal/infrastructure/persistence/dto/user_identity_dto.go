@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FindUserIdentityInput struct {
+	Provider string `json:"provider" validate:"required"`
+	Subject  string `json:"subject" validate:"required"`
+}
+
+type CreateUserIdentityInput struct {
+	UserID   uuid.UUID `json:"user_id" validate:"required"`
+	Provider string    `json:"provider" validate:"required"`
+	Subject  string    `json:"subject" validate:"required"`
+	Email    string    `json:"email"`
+}
+
+type TouchUserIdentityInput struct {
+	ID    uuid.UUID `json:"id" validate:"required"`
+	Email string    `json:"email"`
+}
+
+type UserIdentityOutput struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Provider    string     `json:"provider"`
+	Subject     string     `json:"subject"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func ConvertUserIdentityOutput(identity *domain.UserIdentity) *UserIdentityOutput {
+	return &UserIdentityOutput{
+		ID:          identity.ID,
+		UserID:      identity.UserID,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: identity.LastLoginAt,
+		CreatedAt:   identity.CreatedAt,
+	}
+}
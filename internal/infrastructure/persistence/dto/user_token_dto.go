@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FindUserTokenByHashInput struct {
+	Purpose   domain.UserTokenPurpose `json:"purpose" validate:"required"`
+	TokenHash string                  `json:"token_hash" validate:"required"`
+}
+
+type CreateUserTokenInput struct {
+	UserID    uuid.UUID               `json:"user_id" validate:"required"`
+	Purpose   domain.UserTokenPurpose `json:"purpose" validate:"required"`
+	TokenHash string                  `json:"token_hash" validate:"required"`
+	ExpiresAt time.Time               `json:"expires_at" validate:"required"`
+}
+
+type ConsumeUserTokenInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+}
+
+type InvalidateUserTokensInput struct {
+	UserID  uuid.UUID               `json:"user_id" validate:"required"`
+	Purpose domain.UserTokenPurpose `json:"purpose" validate:"required"`
+}
+
+type UserTokenOutput struct {
+	ID        uuid.UUID               `json:"id"`
+	UserID    uuid.UUID               `json:"user_id"`
+	Purpose   domain.UserTokenPurpose `json:"purpose"`
+	ExpiresAt time.Time               `json:"expires_at"`
+	UsedAt    *time.Time              `json:"used_at"`
+	CreatedAt time.Time               `json:"created_at"`
+}
+
+func ConvertUserTokenOutput(token *domain.UserToken) *UserTokenOutput {
+	return &UserTokenOutput{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		Purpose:   token.Purpose,
+		ExpiresAt: token.ExpiresAt,
+		UsedAt:    token.UsedAt,
+		CreatedAt: token.CreatedAt,
+	}
+}
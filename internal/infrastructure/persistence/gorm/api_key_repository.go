@@ -0,0 +1,83 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) repository.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, input *dto.CreateAPIKeyInput) (*dto.APIKeyOutput, error) {
+	key := domain.APIKey{
+		UserID:    input.UserID,
+		Name:      input.Name,
+		Prefix:    input.Prefix,
+		KeyHash:   input.KeyHash,
+		Scopes:    domain.JoinScopes(input.Scopes),
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := r.db.Create(&key).Error; err != nil {
+		return nil, HandleDBError(err, "api key")
+	}
+	return dto.ConvertAPIKeyOutput(&key), nil
+}
+
+// List はユーザーの失効していないキーを作成日時の新しい順に返します
+func (r *apiKeyRepository) List(ctx context.Context, input *dto.ListAPIKeysInput) ([]dto.APIKeyOutput, error) {
+	var keys []domain.APIKey
+	if err := r.db.Where("user_id = ? AND revoked_at IS NULL", input.UserID).
+		Order("created_at DESC").
+		Find(&keys).Error; err != nil {
+		return nil, HandleDBError(err, "api key")
+	}
+
+	outputs := make([]dto.APIKeyOutput, len(keys))
+	for i, key := range keys {
+		outputs[i] = *dto.ConvertAPIKeyOutput(&key)
+	}
+	return outputs, nil
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, input *dto.FindAPIKeyByHashInput) (*dto.APIKeyOutput, error) {
+	var key domain.APIKey
+	if err := r.db.First(&key, "key_hash = ?", input.KeyHash).Error; err != nil {
+		return nil, HandleDBError(err, "api key")
+	}
+	return dto.ConvertAPIKeyOutput(&key), nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, input *dto.RevokeAPIKeyInput) error {
+	result := r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", input.ID, input.UserID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return HandleDBError(result.Error, "api key")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("api key not found", nil)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) Touch(ctx context.Context, input *dto.TouchAPIKeyInput) error {
+	now := time.Now()
+	result := r.db.Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", input.ID, now.Add(-input.Interval)).
+		Update("last_used_at", now)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "api key")
+	}
+	return nil
+}
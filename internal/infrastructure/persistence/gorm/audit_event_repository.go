@@ -0,0 +1,75 @@
+package persistence_gorm
+
+import (
+	"context"
+	"encoding/json"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type auditEventRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditEventRepository(db *gorm.DB) repository.AuditEventRepository {
+	return &auditEventRepository{db: db}
+}
+
+func (r *auditEventRepository) Create(ctx context.Context, input *dto.CreateAuditEventInput) (*dto.AuditEventOutput, error) {
+	event := domain.AuditEvent{
+		Action:    input.Action,
+		Outcome:   input.Outcome,
+		ActorID:   input.ActorID,
+		TargetID:  input.TargetID,
+		IPAddress: truncate(input.IPAddress, 45),
+		UserAgent: truncate(input.UserAgent, 512),
+	}
+	if len(input.Metadata) > 0 {
+		metadata, err := json.Marshal(input.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		event.Metadata = string(metadata)
+	}
+	if err := r.db.Create(&event).Error; err != nil {
+		return nil, HandleDBError(err, "audit event")
+	}
+	return dto.ConvertAuditEventOutput(&event), nil
+}
+
+// List は条件に一致する記録を新しい順に返します
+func (r *auditEventRepository) List(ctx context.Context, input *dto.ListAuditEventsInput) (*dto.AuditEventListOutput, error) {
+	query := r.db.Model(&domain.AuditEvent{})
+	if input.Action != "" {
+		query = query.Where("action = ?", input.Action)
+	}
+	if input.Outcome != "" {
+		query = query.Where("outcome = ?", input.Outcome)
+	}
+	if input.ActorID != nil {
+		query = query.Where("actor_id = ?", *input.ActorID)
+	}
+	if input.TargetID != nil {
+		query = query.Where("target_id = ?", *input.TargetID)
+	}
+	if input.From != nil {
+		query = query.Where("created_at >= ?", *input.From)
+	}
+	if input.To != nil {
+		query = query.Where("created_at < ?", *input.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, HandleDBError(err, "audit event")
+	}
+
+	var events []*domain.AuditEvent
+	if err := query.Order("created_at DESC").Order("id").Limit(input.Limit).Offset(input.Offset).Find(&events).Error; err != nil {
+		return nil, HandleDBError(err, "audit event")
+	}
+	return dto.ConvertAuditEventListOutput(events, total), nil
+}
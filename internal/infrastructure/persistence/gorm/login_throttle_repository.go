@@ -0,0 +1,134 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loginThrottleRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginThrottleRepository(db *gorm.DB) repository.LoginThrottleRepository {
+	return &loginThrottleRepository{db: db}
+}
+
+func (r *loginThrottleRepository) Find(ctx context.Context, input *dto.FindLoginThrottleInput) (*dto.LoginThrottleOutput, error) {
+	var throttle domain.LoginThrottle
+	if err := r.db.First(&throttle, "scope = ? AND identifier = ?", input.Scope, input.Identifier).Error; err != nil {
+		return nil, HandleDBError(err, "login throttle")
+	}
+	return dto.ConvertLoginThrottleOutput(&throttle), nil
+}
+
+// RecordFailure は失敗回数を1つ増やします。同時に失敗しても取りこぼさないよう UPSERT で加算します。
+// ロック中はログインを試せないため、Window は最後の失敗かロックの解除時刻の遅いほうから数えます
+func (r *loginThrottleRepository) RecordFailure(ctx context.Context, input *dto.RecordLoginFailureInput) (*dto.LoginThrottleOutput, error) {
+	now := time.Now()
+	throttle := domain.LoginThrottle{
+		Scope:          input.Scope,
+		Identifier:     input.Identifier,
+		FailedAttempts: 1,
+		LastFailedAt:   now,
+	}
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "scope"}, {Name: "identifier"}},
+			DoUpdates: clause.Set{
+				{
+					Column: clause.Column{Name: "failed_attempts"},
+					Value: gorm.Expr(
+						"CASE WHEN GREATEST(login_throttles.last_failed_at, login_throttles.locked_until) < ? THEN 1 ELSE login_throttles.failed_attempts + 1 END",
+						now.Add(-input.Window),
+					),
+				},
+				{Column: clause.Column{Name: "last_failed_at"}, Value: now},
+			},
+		},
+		clause.Returning{},
+	).Create(&throttle).Error
+	if err != nil {
+		return nil, HandleDBError(err, "login throttle")
+	}
+	return dto.ConvertLoginThrottleOutput(&throttle), nil
+}
+
+func (r *loginThrottleRepository) Lock(ctx context.Context, input *dto.LockLoginThrottleInput) error {
+	result := r.db.Model(&domain.LoginThrottle{}).
+		Where("scope = ? AND identifier = ?", input.Scope, input.Identifier).
+		Update("locked_until", input.LockedUntil)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "login throttle")
+	}
+	return nil
+}
+
+func (r *loginThrottleRepository) Reset(ctx context.Context, input *dto.ResetLoginThrottleInput) error {
+	if err := r.db.Delete(&domain.LoginThrottle{}, "scope = ? AND identifier = ?", input.Scope, input.Identifier).Error; err != nil {
+		return HandleDBError(err, "login throttle")
+	}
+	return nil
+}
+
+type lockoutEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLockoutEventRepository(db *gorm.DB) repository.LockoutEventRepository {
+	return &lockoutEventRepository{db: db}
+}
+
+func (r *lockoutEventRepository) Create(ctx context.Context, input *dto.CreateLockoutEventInput) (*dto.LockoutEventOutput, error) {
+	event := domain.LockoutEvent{
+		Scope:          input.Scope,
+		Identifier:     input.Identifier,
+		FailedAttempts: input.FailedAttempts,
+		LockedUntil:    input.LockedUntil,
+	}
+	if err := r.db.Create(&event).Error; err != nil {
+		return nil, HandleDBError(err, "lockout event")
+	}
+	return dto.ConvertLockoutEventOutput(&event), nil
+}
+
+func (r *lockoutEventRepository) List(ctx context.Context, input *dto.ListLockoutEventsInput) (*dto.LockoutEventListOutput, error) {
+	query := r.db.Model(&domain.LockoutEvent{})
+	if input.ActiveOnly {
+		query = query.Where("cleared_at IS NULL AND locked_until > ?", time.Now())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, HandleDBError(err, "lockout event")
+	}
+
+	var events []*domain.LockoutEvent
+	if err := query.Order("created_at DESC").Order("id").Limit(input.Limit).Offset(input.Offset).Find(&events).Error; err != nil {
+		return nil, HandleDBError(err, "lockout event")
+	}
+	return dto.ConvertLockoutEventListOutput(events, total), nil
+}
+
+func (r *lockoutEventRepository) FindByID(ctx context.Context, input *dto.FindLockoutEventByIDInput) (*dto.LockoutEventOutput, error) {
+	var event domain.LockoutEvent
+	if err := r.db.First(&event, "id = ?", input.ID).Error; err != nil {
+		return nil, HandleDBError(err, "lockout event")
+	}
+	return dto.ConvertLockoutEventOutput(&event), nil
+}
+
+func (r *lockoutEventRepository) Clear(ctx context.Context, input *dto.ClearLockoutEventsInput) error {
+	result := r.db.Model(&domain.LockoutEvent{}).
+		Where("scope = ? AND identifier = ? AND cleared_at IS NULL", input.Scope, input.Identifier).
+		Update("cleared_at", time.Now())
+	if result.Error != nil {
+		return HandleDBError(result.Error, "lockout event")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type mfaRepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) repository.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func (r *mfaRepository) FindByUserID(ctx context.Context, input *dto.FindMFACredentialInput) (*dto.MFACredentialOutput, error) {
+	var credential domain.MFACredential
+	if err := r.db.First(&credential, "user_id = ?", input.UserID).Error; err != nil {
+		return nil, HandleDBError(err, "mfa credential")
+	}
+	return dto.ConvertMFACredentialOutput(&credential), nil
+}
+
+// Save は未確認の秘密鍵を保存します。確認前にやり直した場合は古い秘密鍵を置き換えます
+func (r *mfaRepository) Save(ctx context.Context, input *dto.SaveMFACredentialInput) error {
+	credential := domain.MFACredential{
+		UserID: input.UserID,
+		Secret: input.Secret,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"secret":         input.Secret,
+			"confirmed_at":   nil,
+			"last_used_step": 0,
+			"updated_at":     time.Now(),
+		}),
+	}).Create(&credential).Error
+	if err != nil {
+		return HandleDBError(err, "mfa credential")
+	}
+	return nil
+}
+
+func (r *mfaRepository) Confirm(ctx context.Context, input *dto.ConfirmMFACredentialInput) error {
+	result := r.db.Model(&domain.MFACredential{}).
+		Where("user_id = ? AND confirmed_at IS NULL", input.UserID).
+		Updates(map[string]interface{}{
+			"confirmed_at":   time.Now(),
+			"last_used_step": input.Step,
+		})
+	if result.Error != nil {
+		return HandleDBError(result.Error, "mfa credential")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("mfa credential not found", nil)
+	}
+	return nil
+}
+
+// UseStep は使用したタイムステップを記録します。同じか古いステップの場合は NotFound を返し、コードの再利用を防ぎます
+func (r *mfaRepository) UseStep(ctx context.Context, input *dto.UseMFAStepInput) error {
+	result := r.db.Model(&domain.MFACredential{}).
+		Where("user_id = ? AND last_used_step < ?", input.UserID, input.Step).
+		Update("last_used_step", input.Step)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "mfa credential")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("mfa code already used", nil)
+	}
+	return nil
+}
+
+func (r *mfaRepository) Delete(ctx context.Context, input *dto.DeleteMFACredentialInput) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.MFARecoveryCode{}, "user_id = ?", input.UserID).Error; err != nil {
+			return HandleDBError(err, "mfa recovery code")
+		}
+		if err := tx.Delete(&domain.MFACredential{}, "user_id = ?", input.UserID).Error; err != nil {
+			return HandleDBError(err, "mfa credential")
+		}
+		return nil
+	})
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, input *dto.ReplaceMFARecoveryCodesInput) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&domain.MFARecoveryCode{}, "user_id = ?", input.UserID).Error; err != nil {
+			return HandleDBError(err, "mfa recovery code")
+		}
+		codes := make([]domain.MFARecoveryCode, len(input.CodeHashes))
+		for i, hash := range input.CodeHashes {
+			codes[i] = domain.MFARecoveryCode{UserID: input.UserID, CodeHash: hash}
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return HandleDBError(err, "mfa recovery code")
+		}
+		return nil
+	})
+}
+
+func (r *mfaRepository) ConsumeRecoveryCode(ctx context.Context, input *dto.ConsumeMFARecoveryCodeInput) error {
+	result := r.db.Model(&domain.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", input.UserID, input.CodeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return HandleDBError(result.Error, "mfa recovery code")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("mfa recovery code not found", nil)
+	}
+	return nil
+}
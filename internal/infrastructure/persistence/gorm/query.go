@@ -0,0 +1,8 @@
+package persistence_gorm
+
+import "strings"
+
+// escapeLike は LIKE 検索のワイルドカード文字をエスケープします
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,78 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) repository.RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) FindByHash(ctx context.Context, input *dto.FindRefreshTokenByHashInput) (*dto.RefreshTokenOutput, error) {
+	var token domain.RefreshToken
+	if err := r.db.First(&token, "token_hash = ?", input.TokenHash).Error; err != nil {
+		return nil, HandleDBError(err, "refresh token")
+	}
+	return dto.ConvertRefreshTokenOutput(&token), nil
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, input *dto.CreateRefreshTokenInput) (*dto.RefreshTokenOutput, error) {
+	token := domain.RefreshToken{
+		UserID:    input.UserID,
+		FamilyID:  input.FamilyID,
+		TokenHash: input.TokenHash,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := r.db.Create(&token).Error; err != nil {
+		return nil, HandleDBError(err, "refresh token")
+	}
+	return dto.ConvertRefreshTokenOutput(&token), nil
+}
+
+// MarkRotated は未使用のリフレッシュトークンを使用済みにします。
+// 既に使用済み・失効済みの場合は NotFound を返すため、同時に使われたトークンも再利用として検知できます。
+func (r *refreshTokenRepository) MarkRotated(ctx context.Context, input *dto.MarkRefreshTokenRotatedInput) error {
+	result := r.db.Model(&domain.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", input.ID).
+		Update("rotated_at", time.Now())
+	if result.Error != nil {
+		return HandleDBError(result.Error, "refresh token")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("refresh token not found", nil)
+	}
+	return nil
+}
+
+func (r *refreshTokenRepository) ListActiveFamilies(ctx context.Context, input *dto.ListActiveRefreshTokenFamiliesInput) ([]uuid.UUID, error) {
+	var familyIDs []uuid.UUID
+	if err := r.db.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", input.UserID).
+		Distinct().
+		Pluck("family_id", &familyIDs).Error; err != nil {
+		return nil, HandleDBError(err, "refresh token")
+	}
+	return familyIDs, nil
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, input *dto.RevokeRefreshTokenFamilyInput) error {
+	result := r.db.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", input.FamilyID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return HandleDBError(result.Error, "refresh token")
+	}
+	return nil
+}
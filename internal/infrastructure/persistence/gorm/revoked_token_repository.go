@@ -0,0 +1,38 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type revokedTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) repository.RevokedTokenRepository {
+	return &revokedTokenRepository{db: db}
+}
+
+func (r *revokedTokenRepository) Create(ctx context.Context, input *dto.CreateRevokedTokenInput) error {
+	token := domain.RevokedToken{
+		TokenID:   input.TokenID,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&token).Error; err != nil {
+		return HandleDBError(err, "revoked token")
+	}
+	return nil
+}
+
+func (r *revokedTokenRepository) IsRevoked(ctx context.Context, input *dto.IsTokenRevokedInput) (bool, error) {
+	var count int64
+	if err := r.db.Model(&domain.RevokedToken{}).Where("token_id = ?", input.TokenID).Count(&count).Error; err != nil {
+		return false, HandleDBError(err, "revoked token")
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,87 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/repository"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// activeSessionCondition はリフレッシュトークンの最新の世代がまだ使えるセッションに絞り込みます。
+// ログアウトや失効はリフレッシュトークンのファミリーに記録されるため、セッション側には状態を持ちません
+const activeSessionCondition = "EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.family_id = sessions.id" +
+	" AND refresh_tokens.rotated_at IS NULL AND refresh_tokens.revoked_at IS NULL AND refresh_tokens.expires_at > ?)"
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) repository.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(ctx context.Context, input *dto.CreateSessionInput) (*dto.SessionOutput, error) {
+	session := domain.Session{
+		ID:             input.ID,
+		UserID:         input.UserID,
+		UserAgent:      truncate(input.UserAgent, 512),
+		IPAddress:      truncate(input.IPAddress, 45),
+		ImpersonatorID: input.ImpersonatorID,
+		LastSeenAt:     time.Now(),
+	}
+	if err := r.db.Create(&session).Error; err != nil {
+		return nil, HandleDBError(err, "session")
+	}
+	return dto.ConvertSessionOutput(&session), nil
+}
+
+// ListActive はユーザーの有効なセッションを最後に使われた順に返します
+func (r *sessionRepository) ListActive(ctx context.Context, input *dto.ListActiveSessionsInput) ([]dto.SessionOutput, error) {
+	var sessions []domain.Session
+	if err := r.db.Where("user_id = ?", input.UserID).
+		Where(activeSessionCondition, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, HandleDBError(err, "session")
+	}
+
+	outputs := make([]dto.SessionOutput, len(sessions))
+	for i, session := range sessions {
+		outputs[i] = *dto.ConvertSessionOutput(&session)
+	}
+	return outputs, nil
+}
+
+func (r *sessionRepository) FindActive(ctx context.Context, input *dto.FindSessionInput) (*dto.SessionOutput, error) {
+	var session domain.Session
+	if err := r.db.Where("id = ? AND user_id = ?", input.ID, input.UserID).
+		Where(activeSessionCondition, time.Now()).
+		First(&session).Error; err != nil {
+		return nil, HandleDBError(err, "session")
+	}
+	return dto.ConvertSessionOutput(&session), nil
+}
+
+func (r *sessionRepository) Touch(ctx context.Context, input *dto.TouchSessionInput) error {
+	now := time.Now()
+	result := r.db.Model(&domain.Session{}).
+		Where("id = ? AND last_seen_at < ?", input.ID, now.Add(-input.Interval)).
+		Update("last_seen_at", now)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "session")
+	}
+	return nil
+}
+
+// truncate は列の長さを超える値を切り詰めます
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+	// drop a multi-byte character cut in half
+	return strings.ToValidUTF8(value[:length], "")
+}
@@ -0,0 +1,77 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) repository.TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(ctx context.Context, input *dto.CreateTagInput) (*dto.TagOutput, error) {
+	tag := domain.Tag{
+		UserID: input.UserID,
+		Name:   input.Name,
+	}
+	if err := r.db.Create(&tag).Error; err != nil {
+		return nil, HandleDBError(err, "tag")
+	}
+	return dto.ConvertTagOutput(&tag), nil
+}
+
+// List はユーザーのタグを名前順に返します
+func (r *tagRepository) List(ctx context.Context, input *dto.ListTagsInput) ([]dto.TagOutput, error) {
+	var tags []domain.Tag
+	if err := r.db.Where("user_id = ?", input.UserID).Order("name, id").Find(&tags).Error; err != nil {
+		return nil, HandleDBError(err, "tag")
+	}
+
+	outputs := make([]dto.TagOutput, len(tags))
+	for i, tag := range tags {
+		outputs[i] = *dto.ConvertTagOutput(&tag)
+	}
+	return outputs, nil
+}
+
+func (r *tagRepository) FindByID(ctx context.Context, input *dto.FindTagInput) (*dto.TagOutput, error) {
+	var tag domain.Tag
+	if err := r.db.First(&tag, "id = ? AND user_id = ?", input.ID, input.UserID).Error; err != nil {
+		return nil, HandleDBError(err, "tag")
+	}
+	return dto.ConvertTagOutput(&tag), nil
+}
+
+func (r *tagRepository) Update(ctx context.Context, input *dto.UpdateTagInput) (*dto.TagOutput, error) {
+	result := r.db.Model(&domain.Tag{}).
+		Where("id = ? AND user_id = ?", input.ID, input.UserID).
+		Update("name", input.Name)
+	if result.Error != nil {
+		return nil, HandleDBError(result.Error, "tag")
+	}
+	if result.RowsAffected == 0 {
+		return nil, apperrors.NewNotFoundError("tag not found", nil)
+	}
+	return r.FindByID(ctx, &dto.FindTagInput{ID: input.ID, UserID: input.UserID})
+}
+
+// Delete はタグを削除します。todo との関連は外部キーの CASCADE で削除されます
+func (r *tagRepository) Delete(ctx context.Context, input *dto.DeleteTagInput) error {
+	result := r.db.Delete(&domain.Tag{}, "id = ? AND user_id = ?", input.ID, input.UserID)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "tag")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("tag not found", nil)
+	}
+	return nil
+}
@@ -4,12 +4,78 @@ import (
 	"context"
 	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/cursor"
 	apperrors "go-boilerplate/internal/pkg/errors"
 	"go-boilerplate/internal/repository"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// todoCursor はページの境界となる todo の並び替えキーの値です。Sort は作成時の並び順で、
+// 別の並び順のカーソルが渡された場合は拒否します。Backward が true の場合は境界より前のページを表します
+type todoCursor struct {
+	Sort     string    `json:"sort"`
+	Values   []string  `json:"values"`
+	ID       uuid.UUID `json:"id"`
+	Backward bool      `json:"backward,omitempty"`
+}
+
+// todoSortColumn は並び替えに使える列です。カーソルには文字列で保存するため、その変換も持ちます
+type todoSortColumn struct {
+	column string
+	value  func(todo *domain.Todo) string
+	parse  func(value string) (any, error)
+}
+
+// todoSortColumns は並び替えを許可する項目の一覧で、ここにない列は SQL に組み込みません
+var todoSortColumns = map[dto.TodoSortField]todoSortColumn{
+	dto.TodoSortCreatedAt: {
+		column: "created_at",
+		value:  func(todo *domain.Todo) string { return todo.CreatedAt.Format(time.RFC3339Nano) },
+		parse:  parseCursorTime,
+	},
+	dto.TodoSortUpdatedAt: {
+		column: "updated_at",
+		value:  func(todo *domain.Todo) string { return todo.UpdatedAt.Format(time.RFC3339Nano) },
+		parse:  parseCursorTime,
+	},
+	dto.TodoSortTitle: {
+		column: "title",
+		value:  func(todo *domain.Todo) string { return todo.Title },
+		parse:  func(value string) (any, error) { return value, nil },
+	},
+	// todos without a due date sort as if due at the end of time so the keyset stays comparable
+	dto.TodoSortDueAt: {
+		column: "COALESCE(due_at, '9999-12-31 00:00:00+00')",
+		value: func(todo *domain.Todo) string {
+			if todo.DueAt == nil {
+				return noDueAt.Format(time.RFC3339Nano)
+			}
+			return todo.DueAt.Format(time.RFC3339Nano)
+		},
+		parse: parseCursorTime,
+	},
+	dto.TodoSortPriority: {
+		column: "priority",
+		value:  func(todo *domain.Todo) string { return strconv.Itoa(int(todo.Priority)) },
+		parse:  func(value string) (any, error) { return strconv.Atoi(value) },
+	},
+}
+
+var noDueAt = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+var defaultTodoSort = []dto.TodoSort{{Field: dto.TodoSortCreatedAt, Desc: true}}
+
+func parseCursorTime(value string) (any, error) {
+	return time.Parse(time.RFC3339Nano, value)
+}
+
 type todoRepository struct {
 	db *gorm.DB
 }
@@ -19,11 +85,190 @@ func NewTodoRepository(db *gorm.DB) repository.TodoRepository {
 }
 
 func (r *todoRepository) FindAll(ctx context.Context, input *dto.FindAllInput) (*dto.TodoListOutput, error) {
+	sort := input.Sort
+	if len(sort) == 0 {
+		sort = defaultTodoSort
+	}
+	columns := make([]todoSortColumn, len(sort))
+	for i, s := range sort {
+		column, ok := todoSortColumns[s.Field]
+		if !ok {
+			return nil, apperrors.NewValidationError("cannot sort by "+string(s.Field), nil)
+		}
+		columns[i] = column
+	}
+	signature := dto.FormatTodoSort(sort)
+
+	var position *todoCursor
+	if input.Cursor != "" {
+		position = &todoCursor{}
+		if err := cursor.Decode(input.Cursor, position); err != nil {
+			return nil, apperrors.NewValidationError("invalid cursor", err)
+		}
+		if position.Sort != signature || len(position.Values) != len(columns) {
+			return nil, apperrors.NewValidationError("cursor does not match the requested sort", nil)
+		}
+	}
+	backward := position != nil && position.Backward
+
+	var total int64
+	if err := r.filterTodos(input).Model(&domain.Todo{}).Count(&total).Error; err != nil {
+		return nil, HandleDBError(err, "todo")
+	}
+
+	query := r.filterTodos(input)
+	if position != nil {
+		condition, args, err := keysetCondition(sort, columns, position)
+		if err != nil {
+			return nil, apperrors.NewValidationError("invalid cursor", err)
+		}
+		query = query.Where(condition, args...)
+	}
+	// walking backwards reverses the order, and the display order is restored afterwards
+	for i, s := range sort {
+		query = query.Order(columns[i].column + orderDirection(s.Desc != backward))
+	}
+	query = query.Order("id" + orderDirection(sort[len(sort)-1].Desc != backward))
+
+	// one extra row tells whether another page follows in the direction of travel
 	var todos []*domain.Todo
-	if err := r.db.Where("user_id = ?", input.UserID.String()).Find(&todos).Error; err != nil {
-		return &dto.TodoListOutput{}, err
+	if err := query.Limit(input.Limit + 1).Find(&todos).Error; err != nil {
+		return nil, HandleDBError(err, "todo")
+	}
+	hasMore := len(todos) > input.Limit
+	if hasMore {
+		todos = todos[:input.Limit]
+	}
+	if backward {
+		slices.Reverse(todos)
 	}
-	return dto.ConvertTodoListOutput(todos, int64(len(todos))), nil
+
+	output := dto.ConvertTodoListOutput(todos, total)
+	if len(todos) == 0 {
+		return output, nil
+	}
+	if err := r.attachTags(output.Todos); err != nil {
+		return nil, err
+	}
+	hasNext := hasMore
+	hasPrev := position != nil
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		next, err := encodeTodoCursor(signature, columns, todos[len(todos)-1], false)
+		if err != nil {
+			return nil, err
+		}
+		output.NextCursor = &next
+	}
+	if hasPrev {
+		prev, err := encodeTodoCursor(signature, columns, todos[0], true)
+		if err != nil {
+			return nil, err
+		}
+		output.PrevCursor = &prev
+	}
+	return output, nil
+}
+
+// filterTodos は一覧と件数の取得で共通の絞り込み条件を組み立てます
+func (r *todoRepository) filterTodos(input *dto.FindAllInput) *gorm.DB {
+	query := r.db.Where("user_id = ?", input.UserID)
+	if input.Query != "" {
+		pattern := "%" + escapeLike(input.Query) + "%"
+		query = query.Where("title ILIKE ? OR content ILIKE ?", pattern, pattern)
+	}
+	if input.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *input.CreatedFrom)
+	}
+	if input.CreatedTo != nil {
+		query = query.Where("created_at < ?", *input.CreatedTo)
+	}
+	if input.UpdatedFrom != nil {
+		query = query.Where("updated_at >= ?", *input.UpdatedFrom)
+	}
+	if input.UpdatedTo != nil {
+		query = query.Where("updated_at < ?", *input.UpdatedTo)
+	}
+	if input.DueFrom != nil {
+		query = query.Where("due_at >= ?", *input.DueFrom)
+	}
+	if input.DueTo != nil {
+		query = query.Where("due_at < ?", *input.DueTo)
+	}
+	if len(input.Statuses) > 0 {
+		query = query.Where("status IN ?", input.Statuses)
+	}
+	if tagIDs := uniqueIDs(input.TagIDs); len(tagIDs) > 0 {
+		if input.MatchAllTags {
+			query = query.Where("(SELECT COUNT(*) FROM todo_tags WHERE todo_tags.todo_id = todos.id AND todo_tags.tag_id IN ?) = ?", tagIDs, len(tagIDs))
+		} else {
+			query = query.Where("EXISTS (SELECT 1 FROM todo_tags WHERE todo_tags.todo_id = todos.id AND todo_tags.tag_id IN ?)", tagIDs)
+		}
+	}
+	return query
+}
+
+// keysetCondition はカーソルの位置より後 (Backward の場合は前) の行を選ぶ条件を組み立てます。
+// 列ごとに昇順と降順が混在できるよう、行値の比較ではなく OR で展開します
+func keysetCondition(sort []dto.TodoSort, columns []todoSortColumn, position *todoCursor) (string, []any, error) {
+	values := make([]any, len(columns))
+	for i, column := range columns {
+		value, err := column.parse(position.Values[i])
+		if err != nil {
+			return "", nil, err
+		}
+		values[i] = value
+	}
+
+	keys := append(slices.Clone(columns), todoSortColumn{column: "id"})
+	values = append(values, position.ID)
+	desc := make([]bool, len(keys))
+	for i := range keys {
+		if i < len(sort) {
+			desc[i] = sort[i].Desc
+		} else {
+			desc[i] = sort[len(sort)-1].Desc
+		}
+	}
+
+	var clauses []string
+	var args []any
+	for i := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].column+" = ?")
+			args = append(args, values[j])
+		}
+		operator := " > ?"
+		if desc[i] != position.Backward {
+			operator = " < ?"
+		}
+		parts = append(parts, keys[i].column+operator)
+		args = append(args, values[i])
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+	return strings.Join(clauses, " OR "), args, nil
+}
+
+func encodeTodoCursor(signature string, columns []todoSortColumn, todo *domain.Todo, backward bool) (string, error) {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = column.value(todo)
+	}
+	encoded, err := cursor.Encode(&todoCursor{Sort: signature, Values: values, ID: todo.ID, Backward: backward})
+	if err != nil {
+		return "", apperrors.NewInternalError("failed to encode cursor", err)
+	}
+	return encoded, nil
+}
+
+func orderDirection(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
 }
 
 func (r *todoRepository) FindByID(ctx context.Context, input *dto.FindByIDInput) (*dto.TodoOutput, error) {
@@ -32,7 +277,11 @@ func (r *todoRepository) FindByID(ctx context.Context, input *dto.FindByIDInput)
 		return nil, HandleDBError(err, "todo")
 	}
 
-	return dto.ConvertTodoOutput(&todo), nil
+	outputs := []dto.TodoOutput{*dto.ConvertTodoOutput(&todo)}
+	if err := r.attachTags(outputs); err != nil {
+		return nil, err
+	}
+	return &outputs[0], nil
 }
 
 func (r *todoRepository) Create(ctx context.Context, input *dto.CreateTodoInput) (*dto.TodoOutput, error) {
@@ -40,22 +289,69 @@ func (r *todoRepository) Create(ctx context.Context, input *dto.CreateTodoInput)
 	todo.UserID = input.UserID
 	todo.Title = input.Title
 	todo.Content = input.Content
-	if err := r.db.Create(&todo).Error; err != nil {
-		return nil, HandleDBError(err, "todo")
+	todo.DueAt = input.DueAt
+	todo.Priority = input.Priority
+	todo.Status = domain.TodoStatusOpen
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&todo).Error; err != nil {
+			return HandleDBError(err, "todo")
+		}
+		return replaceTodoTags(tx, input.UserID, todo.ID, input.TagIDs)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return dto.ConvertTodoOutput(&todo), nil
+	return r.FindByID(ctx, &dto.FindByIDInput{ID: todo.ID, UserID: input.UserID})
 }
 
 func (r *todoRepository) Update(ctx context.Context, input *dto.UpdateTodoInput) (*dto.TodoOutput, error) {
-	var todo domain.Todo
-	todo.ID = input.ID
-	todo.UserID = input.UserID
-	todo.Title = input.Title
-	todo.Content = input.Content
-	if err := r.db.Save(&todo).Error; err != nil {
-		return nil, HandleDBError(err, "todo")
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// only the editable columns are written so the status and timestamps are kept
+		result := tx.Model(&domain.Todo{}).
+			Where("id = ? AND user_id = ?", input.ID, input.UserID).
+			Updates(map[string]interface{}{
+				"title":    input.Title,
+				"content":  input.Content,
+				"due_at":   input.DueAt,
+				"priority": input.Priority,
+			})
+		if result.Error != nil {
+			return HandleDBError(result.Error, "todo")
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NewNotFoundError("todo not found", nil)
+		}
+		if input.TagIDs == nil {
+			return nil
+		}
+		return replaceTodoTags(tx, input.UserID, input.ID, *input.TagIDs)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return dto.ConvertTodoOutput(&todo), nil
+	return r.FindByID(ctx, &dto.FindByIDInput{ID: input.ID, UserID: input.UserID})
+}
+
+// UpdateStatus はステータスが CurrentStatus のままの場合だけ更新します。
+// 同時に別のリクエストがステータスを変えていた場合は、遷移できないものとして BusinessRuleError を返します
+func (r *todoRepository) UpdateStatus(ctx context.Context, input *dto.UpdateTodoStatusInput) (*dto.TodoOutput, error) {
+	result := r.db.Model(&domain.Todo{}).
+		Where("id = ? AND user_id = ? AND status = ?", input.ID, input.UserID, input.CurrentStatus).
+		Updates(map[string]interface{}{
+			"status":       input.Status,
+			"completed_at": input.CompletedAt,
+		})
+	if result.Error != nil {
+		return nil, HandleDBError(result.Error, "todo")
+	}
+	if result.RowsAffected == 0 {
+		// tell a missing todo apart from one whose status changed underneath us
+		if _, err := r.FindByID(ctx, &dto.FindByIDInput{ID: input.ID, UserID: input.UserID}); err != nil {
+			return nil, err
+		}
+		return nil, apperrors.NewBusinessRuleError("todo status was changed by another request", nil)
+	}
+	return r.FindByID(ctx, &dto.FindByIDInput{ID: input.ID, UserID: input.UserID})
 }
 
 func (r *todoRepository) Delete(ctx context.Context, input *dto.DeleteTodoInput) error {
@@ -67,4 +363,93 @@ func (r *todoRepository) Delete(ctx context.Context, input *dto.DeleteTodoInput)
 		return apperrors.NewNotFoundError("todo not found", result.Error)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// todoTagRow は todo ごとのタグをまとめて読み込むための行です
+type todoTagRow struct {
+	TodoID    uuid.UUID
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// attachTags は todo のタグを 1 回のクエリでまとめて読み込み、todo ごとに名前順で設定します
+func (r *todoRepository) attachTags(todos []dto.TodoOutput) error {
+	if len(todos) == 0 {
+		return nil
+	}
+	todoIDs := make([]uuid.UUID, len(todos))
+	for i, todo := range todos {
+		todoIDs[i] = todo.ID
+	}
+
+	var rows []todoTagRow
+	if err := r.db.Table("tags").
+		Select("todo_tags.todo_id, tags.id, tags.user_id, tags.name, tags.created_at, tags.updated_at").
+		Joins("JOIN todo_tags ON todo_tags.tag_id = tags.id").
+		Where("todo_tags.todo_id IN ?", todoIDs).
+		Order("tags.name, tags.id").
+		Scan(&rows).Error; err != nil {
+		return HandleDBError(err, "tag")
+	}
+
+	tagsByTodo := make(map[uuid.UUID][]dto.TagOutput, len(todos))
+	for _, row := range rows {
+		tagsByTodo[row.TodoID] = append(tagsByTodo[row.TodoID], dto.TagOutput{
+			ID:        row.ID,
+			UserID:    row.UserID,
+			Name:      row.Name,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		})
+	}
+	for i := range todos {
+		if tags, ok := tagsByTodo[todos[i].ID]; ok {
+			todos[i].Tags = tags
+		}
+	}
+	return nil
+}
+
+// replaceTodoTags は todo のタグを tagIDs に置き換えます。他のユーザーのタグや存在しないタグは拒否します
+func replaceTodoTags(tx *gorm.DB, userID uuid.UUID, todoID uuid.UUID, tagIDs []uuid.UUID) error {
+	tagIDs = uniqueIDs(tagIDs)
+	if len(tagIDs) > 0 {
+		var count int64
+		if err := tx.Model(&domain.Tag{}).Where("user_id = ? AND id IN ?", userID, tagIDs).Count(&count).Error; err != nil {
+			return HandleDBError(err, "tag")
+		}
+		if count != int64(len(tagIDs)) {
+			return apperrors.NewValidationError("tag not found", nil)
+		}
+	}
+
+	if err := tx.Where("todo_id = ?", todoID).Delete(&domain.TodoTag{}).Error; err != nil {
+		return HandleDBError(err, "todo tag")
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	links := make([]domain.TodoTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		links[i] = domain.TodoTag{TodoID: todoID, TagID: tagID}
+	}
+	if err := tx.Omit(clause.Associations).Create(&links).Error; err != nil {
+		return HandleDBError(err, "todo tag")
+	}
+	return nil
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,55 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) repository.UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Find(ctx context.Context, input *dto.FindUserIdentityInput) (*dto.UserIdentityOutput, error) {
+	var identity domain.UserIdentity
+	if err := r.db.First(&identity, "provider = ? AND subject = ?", input.Provider, input.Subject).Error; err != nil {
+		return nil, HandleDBError(err, "identity")
+	}
+	return dto.ConvertUserIdentityOutput(&identity), nil
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, input *dto.CreateUserIdentityInput) (*dto.UserIdentityOutput, error) {
+	now := time.Now()
+	identity := domain.UserIdentity{
+		UserID:      input.UserID,
+		Provider:    input.Provider,
+		Subject:     input.Subject,
+		Email:       input.Email,
+		LastLoginAt: &now,
+	}
+	if err := r.db.Create(&identity).Error; err != nil {
+		return nil, HandleDBError(err, "identity")
+	}
+	return dto.ConvertUserIdentityOutput(&identity), nil
+}
+
+// Touch はログイン日時と、プロバイダー側で変わっている可能性のあるメールアドレスを更新します
+func (r *userIdentityRepository) Touch(ctx context.Context, input *dto.TouchUserIdentityInput) error {
+	if err := r.db.Model(&domain.UserIdentity{}).
+		Where("id = ?", input.ID).
+		Updates(map[string]interface{}{
+			"email":         input.Email,
+			"last_login_at": time.Now(),
+		}).Error; err != nil {
+		return HandleDBError(err, "identity")
+	}
+	return nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
 	"go-boilerplate/internal/repository"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -17,9 +19,17 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 	return &userRepository{db: db}
 }
 
+func (r *userRepository) FindByID(ctx context.Context, input *dto.FindUserByIDInput) (*dto.UserOutput, error) {
+	var user domain.User
+	if err := r.db.Where("deleted_at IS NULL").First(&user, "id = ?", input.ID).Error; err != nil {
+		return nil, HandleDBError(err, "user")
+	}
+	return dto.ConvertUserOutput(&user), nil
+}
+
 func (r *userRepository) FindByEmail(ctx context.Context, input *dto.FindUserByEmailInput) (*dto.UserOutput, error) {
 	var user domain.User
-	if err := r.db.First(&user, "email = ?", input.Email).Error; err != nil {
+	if err := r.db.Where("deleted_at IS NULL").First(&user, "email = ?", input.Email).Error; err != nil {
 		return nil, HandleDBError(err, "user")
 	}
 	return dto.ConvertUserOutput(&user), nil
@@ -30,9 +40,120 @@ func (r *userRepository) Create(ctx context.Context, input *dto.CreateUserInput)
 		Name:     input.Name,
 		Email:    input.Email,
 		Password: input.Password,
+		Role:     input.Role,
 	}
 	if err := r.db.Create(&user).Error; err != nil {
 		return nil, HandleDBError(err, "user")
 	}
 	return dto.ConvertUserOutput(&user), nil
-}
\ No newline at end of file
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, input *dto.UpdateUserPasswordInput) error {
+	result := r.db.Model(&domain.User{}).Where("id = ? AND deleted_at IS NULL", input.ID).Update("password", input.Password)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "user")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("user not found", nil)
+	}
+	return nil
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, input *dto.MarkUserEmailVerifiedInput) error {
+	result := r.db.Model(&domain.User{}).
+		Where("id = ? AND email_verified_at IS NULL", input.ID).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		return HandleDBError(result.Error, "user")
+	}
+	return nil
+}
+
+func (r *userRepository) List(ctx context.Context, input *dto.ListUsersInput) (*dto.UserListOutput, error) {
+	query := r.db.Model(&domain.User{}).Where("deleted_at IS NULL")
+	if input.Query != "" {
+		pattern := "%" + escapeLike(input.Query) + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, HandleDBError(err, "user")
+	}
+
+	var users []*domain.User
+	if err := query.Order("created_at DESC").Order("id").Limit(input.Limit).Offset(input.Offset).Find(&users).Error; err != nil {
+		return nil, HandleDBError(err, "user")
+	}
+	return dto.ConvertUserListOutput(users, total), nil
+}
+
+func (r *userRepository) SetDisabled(ctx context.Context, input *dto.SetUserDisabledInput) error {
+	var disabledAt *time.Time
+	if input.Disabled {
+		now := time.Now()
+		disabledAt = &now
+	}
+	result := r.db.Model(&domain.User{}).Where("id = ? AND deleted_at IS NULL", input.ID).Update("disabled_at", disabledAt)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "user")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("user not found", nil)
+	}
+	return nil
+}
+
+func (r *userRepository) Delete(ctx context.Context, input *dto.DeleteUserInput) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", input.ID).Delete(&domain.Todo{}).Error; err != nil {
+			return HandleDBError(err, "todo")
+		}
+		result := tx.Delete(&domain.User{}, "id = ?", input.ID)
+		if result.Error != nil {
+			return HandleDBError(result.Error, "user")
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NewNotFoundError("user not found", nil)
+		}
+		return nil
+	})
+}
+
+func (r *userRepository) Update(ctx context.Context, input *dto.UpdateUserInput) (*dto.UserOutput, error) {
+	result := r.db.Model(&domain.User{}).
+		Where("id = ? AND deleted_at IS NULL", input.ID).
+		Updates(map[string]interface{}{
+			"name":              input.Name,
+			"email":             input.Email,
+			"timezone":          input.Timezone,
+			"email_verified_at": input.EmailVerifiedAt,
+		})
+	if result.Error != nil {
+		return nil, HandleDBError(result.Error, "user")
+	}
+	if result.RowsAffected == 0 {
+		return nil, apperrors.NewNotFoundError("user not found", nil)
+	}
+	return r.FindByID(ctx, &dto.FindUserByIDInput{ID: input.ID})
+}
+
+// SoftDelete は deleted_at を設定し、外部プロバイダーとの紐付けを削除します。
+// メールアドレスの一意制約は削除されていないユーザーだけが対象なので、同じアドレスやプロバイダーのアカウントで再登録できます
+func (r *userRepository) SoftDelete(ctx context.Context, input *dto.DeleteUserInput) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.User{}).
+			Where("id = ? AND deleted_at IS NULL", input.ID).
+			Update("deleted_at", time.Now())
+		if result.Error != nil {
+			return HandleDBError(result.Error, "user")
+		}
+		if result.RowsAffected == 0 {
+			return apperrors.NewNotFoundError("user not found", nil)
+		}
+		if err := tx.Where("user_id = ?", input.ID).Delete(&domain.UserIdentity{}).Error; err != nil {
+			return HandleDBError(err, "identity")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package persistence_gorm
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) repository.UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) FindByHash(ctx context.Context, input *dto.FindUserTokenByHashInput) (*dto.UserTokenOutput, error) {
+	var token domain.UserToken
+	if err := r.db.First(&token, "purpose = ? AND token_hash = ?", input.Purpose, input.TokenHash).Error; err != nil {
+		return nil, HandleDBError(err, "token")
+	}
+	return dto.ConvertUserTokenOutput(&token), nil
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, input *dto.CreateUserTokenInput) (*dto.UserTokenOutput, error) {
+	token := domain.UserToken{
+		UserID:    input.UserID,
+		Purpose:   input.Purpose,
+		TokenHash: input.TokenHash,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if err := r.db.Create(&token).Error; err != nil {
+		return nil, HandleDBError(err, "token")
+	}
+	return dto.ConvertUserTokenOutput(&token), nil
+}
+
+// Consume はトークンを使用済みにします。既に使用済みまたは期限切れの場合は NotFound を返します
+func (r *userTokenRepository) Consume(ctx context.Context, input *dto.ConsumeUserTokenInput) error {
+	now := time.Now()
+	result := r.db.Model(&domain.UserToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", input.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return HandleDBError(result.Error, "token")
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NewNotFoundError("token not found", nil)
+	}
+	return nil
+}
+
+func (r *userTokenRepository) Invalidate(ctx context.Context, input *dto.InvalidateUserTokensInput) error {
+	result := r.db.Model(&domain.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", input.UserID, input.Purpose).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return HandleDBError(result.Error, "token")
+	}
+	return nil
+}
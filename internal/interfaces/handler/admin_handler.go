@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type AdminHandler interface {
+	RegisterAdminHandlers(r *mux.Router)
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	DisableUser(w http.ResponseWriter, r *http.Request)
+	EnableUser(w http.ResponseWriter, r *http.Request)
+	ForcePasswordReset(w http.ResponseWriter, r *http.Request)
+	DeleteUser(w http.ResponseWriter, r *http.Request)
+	ListLockouts(w http.ResponseWriter, r *http.Request)
+	ClearLockout(w http.ResponseWriter, r *http.Request)
+	ImpersonateUser(w http.ResponseWriter, r *http.Request)
+	ListAuditEvents(w http.ResponseWriter, r *http.Request)
+}
+
+type adminHandler struct {
+	BaseHandler
+	adminUseCase usecase.AdminUseCase
+}
+
+func NewAdminHandler(base BaseHandler, adminUseCase usecase.AdminUseCase) AdminHandler {
+	return &adminHandler{BaseHandler: base, adminUseCase: adminUseCase}
+}
+
+func (h *adminHandler) RegisterAdminHandlers(r *mux.Router) {
+	adminRouter := r.PathPrefix(constants.AdminPath).Subrouter()
+	adminRouter.Use(h.authMiddleware)
+
+	canRead := h.RequirePermission(domain.PermissionUsersRead)
+	canWrite := h.RequirePermission(domain.PermissionUsersWrite)
+	canImpersonate := h.RequirePermission(domain.PermissionUsersImpersonate)
+
+	adminRouter.Handle("/users", canRead(http.HandlerFunc(h.ListUsers))).Methods(http.MethodGet, http.MethodOptions)
+	adminRouter.Handle("/users/{id}", canRead(http.HandlerFunc(h.GetUser))).Methods(http.MethodGet, http.MethodOptions)
+	adminRouter.Handle("/users/{id}/disable", canWrite(http.HandlerFunc(h.DisableUser))).Methods(http.MethodPost, http.MethodOptions)
+	adminRouter.Handle("/users/{id}/enable", canWrite(http.HandlerFunc(h.EnableUser))).Methods(http.MethodPost, http.MethodOptions)
+	adminRouter.Handle("/users/{id}/password-reset", canWrite(http.HandlerFunc(h.ForcePasswordReset))).Methods(http.MethodPost, http.MethodOptions)
+	adminRouter.Handle("/users/{id}", canWrite(http.HandlerFunc(h.DeleteUser))).Methods(http.MethodDelete, http.MethodOptions)
+	adminRouter.Handle("/lockouts", canRead(http.HandlerFunc(h.ListLockouts))).Methods(http.MethodGet, http.MethodOptions)
+	adminRouter.Handle("/lockouts/{id}", canWrite(http.HandlerFunc(h.ClearLockout))).Methods(http.MethodDelete, http.MethodOptions)
+	adminRouter.Handle("/audit-events", canRead(http.HandlerFunc(h.ListAuditEvents))).Methods(http.MethodGet, http.MethodOptions)
+	adminRouter.Handle("/users/{id}/impersonate", h.sessionOnlyMiddleware(h.noImpersonationMiddleware(canImpersonate(http.HandlerFunc(h.ImpersonateUser))))).Methods(http.MethodPost, http.MethodOptions)
+}
+
+func (h *adminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	page, err := h.getIntQuery(r, "page", 1)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	perPage, err := h.getIntQuery(r, "per_page", input.DefaultPerPage)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	output, err := h.adminUseCase.ListUsers(ctx, &input.ListUsersInput{
+		Query:   query.Get("q"),
+		Page:    page,
+		PerPage: perPage,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *adminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	input, err := h.userInput(r)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	output, err := h.adminUseCase.GetUser(r.Context(), input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *adminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	input, err := h.userInput(r)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	output, err := h.adminUseCase.DisableUser(r.Context(), input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *adminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	input, err := h.userInput(r)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	output, err := h.adminUseCase.EnableUser(r.Context(), input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *adminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	input, err := h.userInput(r)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	if err := h.adminUseCase.ForcePasswordReset(r.Context(), input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusAccepted, nil)
+}
+
+func (h *adminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	input, err := h.userInput(r)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	if err := h.adminUseCase.DeleteUser(r.Context(), input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *adminHandler) ListLockouts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, err := h.getIntQuery(r, "page", 1)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	perPage, err := h.getIntQuery(r, "per_page", input.DefaultPerPage)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	output, err := h.adminUseCase.ListLockouts(ctx, &input.ListLockoutsInput{
+		ActiveOnly: r.URL.Query().Get("active") == "true",
+		Page:       page,
+		PerPage:    perPage,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *adminHandler) ClearLockout(w http.ResponseWriter, r *http.Request) {
+	lockoutID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid lockout id", err))
+		return
+	}
+
+	if err := h.adminUseCase.ClearLockout(r.Context(), &input.ClearLockoutInput{
+		ID:        lockoutID,
+		ActorID:   h.getCurrentUser(r).ID,
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *adminHandler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid user id", err))
+		return
+	}
+
+	// returned as a bearer token so it never replaces the admin's own session cookie
+	output, err := h.adminUseCase.ImpersonateUser(r.Context(), &input.ImpersonateUserInput{
+		ID:        userID,
+		ActorID:   h.getCurrentUser(r).ID,
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, output)
+}
+
+func (h *adminHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	page, err := h.getIntQuery(r, "page", 1)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	perPage, err := h.getIntQuery(r, "per_page", input.DefaultPerPage)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	actorID, err := h.getUUIDQuery(r, "actor_id")
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	targetID, err := h.getUUIDQuery(r, "target_id")
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	from, err := h.getTimeQuery(r, "from")
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	to, err := h.getTimeQuery(r, "to")
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	output, err := h.adminUseCase.ListAuditEvents(ctx, &input.ListAuditEventsInput{
+		Action:   domain.AuditAction(query.Get("action")),
+		Outcome:  domain.AuditOutcome(query.Get("outcome")),
+		ActorID:  actorID,
+		TargetID: targetID,
+		From:     from,
+		To:       to,
+		Page:     page,
+		PerPage:  perPage,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *adminHandler) userInput(r *http.Request) (*input.AdminUserInput, error) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid user id", err)
+	}
+	return &input.AdminUserInput{
+		ID:        userID,
+		ActorID:   h.getCurrentUser(r).ID,
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"encoding/json"
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler interface {
+	RegisterAPIKeyHandlers(r *mux.Router)
+	ListAPIKeys(w http.ResponseWriter, r *http.Request)
+	CreateAPIKey(w http.ResponseWriter, r *http.Request)
+	RevokeAPIKey(w http.ResponseWriter, r *http.Request)
+}
+
+type apiKeyHandler struct {
+	BaseHandler
+	apiKeyUseCase usecase.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(base BaseHandler, apiKeyUseCase usecase.APIKeyUseCase) APIKeyHandler {
+	return &apiKeyHandler{BaseHandler: base, apiKeyUseCase: apiKeyUseCase}
+}
+
+func (h *apiKeyHandler) RegisterAPIKeyHandlers(r *mux.Router) {
+	tokenRouter := r.PathPrefix(constants.TokensPath).Subrouter()
+	// a key must not be able to mint or revoke other keys
+	tokenRouter.Use(h.authMiddleware, h.sessionOnlyMiddleware, h.noImpersonationMiddleware)
+
+	tokenRouter.HandleFunc("", h.ListAPIKeys).Methods(http.MethodGet, http.MethodOptions)
+	tokenRouter.HandleFunc("", h.CreateAPIKey).Methods(http.MethodPost, http.MethodOptions)
+	tokenRouter.HandleFunc("/{id}", h.RevokeAPIKey).Methods(http.MethodDelete, http.MethodOptions)
+}
+
+func (h *apiKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := h.getCurrentUser(r)
+
+	output, err := h.apiKeyUseCase.ListAPIKeys(ctx, &input.ListAPIKeysInput{UserID: user.ID})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *apiKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input input.CreateAPIKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.UserID = h.getCurrentUser(r).ID
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	output, err := h.apiKeyUseCase.CreateAPIKey(ctx, &input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, output)
+}
+
+func (h *apiKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	keyID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid api key id", err))
+		return
+	}
+
+	if err := h.apiKeyUseCase.RevokeAPIKey(ctx, &input.RevokeAPIKeyInput{
+		ID:        keyID,
+		UserID:    h.getCurrentUser(r).ID,
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
@@ -14,27 +14,55 @@ import (
 type AuthHandler interface {
 	RegisterAuthHandlers(r *mux.Router)
 	Login(w http.ResponseWriter, r *http.Request)
+	VerifyMFA(w http.ResponseWriter, r *http.Request)
+	RequestMagicLink(w http.ResponseWriter, r *http.Request)
+	ConsumeMagicLink(w http.ResponseWriter, r *http.Request)
+	StartOIDC(w http.ResponseWriter, r *http.Request)
+	OIDCCallback(w http.ResponseWriter, r *http.Request)
 	Signup(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+	LogoutAll(w http.ResponseWriter, r *http.Request)
+	ForgotPassword(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
+	VerifyEmail(w http.ResponseWriter, r *http.Request)
+	ResendVerificationEmail(w http.ResponseWriter, r *http.Request)
 	CheckAuthentication(w http.ResponseWriter, r *http.Request)
+	GetJWKS(w http.ResponseWriter, r *http.Request)
 }
 
+const oidcStateCookieName = "oidc_state"
+
 type authHandler struct {
 	BaseHandler
 	authUseCase usecase.AuthUseCase
 }
 
-func NewAuthHandler(authUseCase usecase.AuthUseCase) AuthHandler {
-	return &authHandler{authUseCase: authUseCase}
+func NewAuthHandler(base BaseHandler, authUseCase usecase.AuthUseCase) AuthHandler {
+	return &authHandler{BaseHandler: base, authUseCase: authUseCase}
 }
 
 func (h *authHandler) RegisterAuthHandlers(r *mux.Router) {
 	authRouter := r.PathPrefix(constants.AuthPath).Subrouter()
 	isAuthCheckRouter := r.PathPrefix(constants.AuthPath).Subrouter()
-	isAuthCheckRouter.Use(h.authMiddleware)
+	isAuthCheckRouter.Use(h.authMiddleware, h.sessionOnlyMiddleware)
 
+	r.HandleFunc(constants.JWKSPath, h.GetJWKS).Methods(http.MethodGet, http.MethodOptions)
 	authRouter.HandleFunc("/login", h.Login).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc("/mfa/verify", h.VerifyMFA).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc("/magic-link", h.RequestMagicLink).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc("/magic-link/consume", h.ConsumeMagicLink).Methods(http.MethodGet, http.MethodOptions)
+	authRouter.HandleFunc("/oidc/{provider}/start", h.StartOIDC).Methods(http.MethodGet, http.MethodOptions)
+	authRouter.HandleFunc("/oidc/{provider}/callback", h.OIDCCallback).Methods(http.MethodGet, http.MethodOptions)
 	authRouter.HandleFunc("/signup", h.Signup).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc("/refresh", h.Refresh).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc("/password/forgot", h.ForgotPassword).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc("/password/reset", h.ResetPassword).Methods(http.MethodPost, http.MethodOptions)
+	authRouter.HandleFunc("/verify", h.VerifyEmail).Methods(http.MethodGet, http.MethodOptions)
 	isAuthCheckRouter.HandleFunc("/authentication", h.CheckAuthentication).Methods(http.MethodPost, http.MethodOptions)
+	isAuthCheckRouter.HandleFunc("/logout", h.Logout).Methods(http.MethodPost, http.MethodOptions)
+	isAuthCheckRouter.Handle("/logout-all", h.noImpersonationMiddleware(http.HandlerFunc(h.LogoutAll))).Methods(http.MethodPost, http.MethodOptions)
+	isAuthCheckRouter.Handle("/verify/resend", h.noImpersonationMiddleware(http.HandlerFunc(h.ResendVerificationEmail))).Methods(http.MethodPost, http.MethodOptions)
 }
 
 func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -45,6 +73,8 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
 		return
 	}
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
 
 	output, err := h.authUseCase.Login(ctx, input)
 	if err != nil {
@@ -52,7 +82,124 @@ func (h *authHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, output)
+	h.respondAuth(w, http.StatusOK, output)
+}
+
+func (h *authHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	input := &input.VerifyMFAInput{}
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	output, err := h.authUseCase.VerifyMFA(ctx, input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondAuth(w, http.StatusOK, output)
+}
+
+func (h *authHandler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	input := &input.RequestMagicLinkInput{}
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	if err := h.authUseCase.RequestMagicLink(ctx, input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusAccepted, nil)
+}
+
+func (h *authHandler) ConsumeMagicLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	output, err := h.authUseCase.ConsumeMagicLink(ctx, &input.ConsumeMagicLinkInput{
+		Token:     r.URL.Query().Get("token"),
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondAuth(w, http.StatusOK, output)
+}
+
+func (h *authHandler) StartOIDC(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	output, err := h.authUseCase.StartOIDC(ctx, &input.StartOIDCInput{Provider: vars["provider"]})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	// SameSite=Lax so the cookie survives the top-level redirect back from the provider
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    output.StateToken,
+		Path:     constants.AuthPath + "/oidc",
+		MaxAge:   int(output.ExpiresIn),
+		HttpOnly: true,
+		Secure:   h.config.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, output.AuthorizationURL, http.StatusFound)
+}
+
+func (h *authHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+
+	// the state cookie is single use whatever the outcome
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Path:     constants.AuthPath + "/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.config.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	if providerError := query.Get("error"); providerError != "" {
+		h.respondError(w, apperrors.NewUnauthorizedError("identity provider returned "+providerError, nil))
+		return
+	}
+
+	input := &input.OIDCCallbackInput{
+		Provider:  vars["provider"],
+		Code:      query.Get("code"),
+		State:     query.Get("state"),
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+	if cookie, err := r.Cookie(oidcStateCookieName); err == nil {
+		input.StateToken = cookie.Value
+	}
+
+	output, err := h.authUseCase.OIDCCallback(ctx, input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondAuth(w, http.StatusOK, output)
 }
 
 func (h *authHandler) Signup(w http.ResponseWriter, r *http.Request) {
@@ -63,6 +210,8 @@ func (h *authHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
 		return
 	}
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
 
 	output, err := h.authUseCase.RegisterUser(ctx, input)
 	if err != nil {
@@ -70,18 +219,158 @@ func (h *authHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.respondJSON(w, http.StatusCreated, output)
+	h.respondAuth(w, http.StatusCreated, output)
+}
+
+func (h *authHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	input := &input.RefreshTokenInput{}
+	if refreshToken := h.cookieValue(r, refreshTokenCookieName); refreshToken != "" {
+		if err := h.checkCSRF(r); err != nil {
+			h.respondError(w, err)
+			return
+		}
+		input.RefreshToken = refreshToken
+	} else if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	output, err := h.authUseCase.RefreshToken(ctx, input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondAuth(w, http.StatusOK, output)
 }
 
 func (h *authHandler) CheckAuthentication(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	email := h.getUserEmail(r)
+	user := h.getCurrentUser(r)
+
+	output, err := h.authUseCase.CheckAuthentication(ctx, &input.CheckAuthenticationInput{UserID: user.ID})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *authHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	authenticated := h.getAuthenticated(r)
+
+	err := h.authUseCase.Logout(ctx, &input.LogoutInput{
+		TokenID:        authenticated.TokenID,
+		SessionID:      authenticated.SessionID,
+		ExpiresAt:      authenticated.ExpiresAt,
+		UserID:         authenticated.User.ID,
+		ImpersonatorID: authenticated.ImpersonatorID,
+		IPAddress:      h.clientIP(r),
+		UserAgent:      r.UserAgent(),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.clearSessionCookies(w)
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *authHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := h.getCurrentUser(r)
+
+	err := h.authUseCase.LogoutAll(ctx, &input.LogoutAllInput{
+		UserID:    user.ID,
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.clearSessionCookies(w)
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *authHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	input := &input.ForgotPasswordInput{}
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+
+	if err := h.authUseCase.ForgotPassword(ctx, input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusAccepted, nil)
+}
+
+func (h *authHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	input := &input.ResetPasswordInput{}
+	if err := json.NewDecoder(r.Body).Decode(input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	if err := h.authUseCase.ResetPassword(ctx, input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *authHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	output, err := h.authUseCase.VerifyEmail(ctx, &input.VerifyEmailInput{Token: r.URL.Query().Get("token")})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *authHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := h.getCurrentUser(r)
+
+	if err := h.authUseCase.ResendVerificationEmail(ctx, &input.ResendVerificationEmailInput{UserID: user.ID}); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusAccepted, nil)
+}
+
+func (h *authHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	output, err := h.authUseCase.CheckAuthentication(ctx, &input.CheckAuthenticationInput{Email: email})
+	output, err := h.authUseCase.GetJWKS(ctx)
 	if err != nil {
 		h.respondError(w, err)
 		return
 	}
 
+	// verifiers refetch on an unknown kid, so a short cache is enough for rotation
+	w.Header().Set("Cache-Control", "public, max-age=300")
 	h.respondJSON(w, http.StatusOK, output)
 }
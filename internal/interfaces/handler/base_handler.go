@@ -4,15 +4,45 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"go-boilerplate/internal/domain"
 	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"go-boilerplate/internal/usecase/output"
+	"math"
+	"net"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
-type BaseHandler struct{}
+type BaseHandlerConfig struct {
+	// RequireVerifiedEmail が true の場合、メールアドレス未確認のユーザーは todo API を利用できません
+	RequireVerifiedEmail bool
+	// TrustProxyHeaders が true の場合、X-Forwarded-For をクライアントの IP アドレスとして扱います
+	TrustProxyHeaders bool
+	// TrustedProxyHops は手前にある信頼できるプロキシの数です。X-Forwarded-For の右からこの数番目の値を使います
+	TrustedProxyHops int
+	// SecureCookies が true の場合、Cookie に Secure 属性を付け HTTPS でのみ送信させます
+	SecureCookies bool
+	// CookieSessions が true の場合、ログイン時にトークンを HttpOnly Cookie に保存し、
+	// Authorization ヘッダーがないリクエストは Cookie で認証します。状態を変更するリクエストには CSRF トークンが必要です
+	CookieSessions bool
+	CookieSameSite http.SameSite
+}
+
+type BaseHandler struct {
+	authUseCase usecase.AuthUseCase
+	config      BaseHandlerConfig
+}
+
+func NewBaseHandler(authUseCase usecase.AuthUseCase, config BaseHandlerConfig) BaseHandler {
+	return BaseHandler{authUseCase: authUseCase, config: config}
+}
 
 type ErrorResponse struct {
 	Code    string `json:"code"`
@@ -23,11 +53,6 @@ type contextKey string
 
 const userContextKey contextKey = "user"
 
-type Claims struct {
-	Email string `json:"email"`
-	jwt.RegisteredClaims
-}
-
 func (h *BaseHandler) respondJSON(w http.ResponseWriter, status int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
@@ -61,6 +86,8 @@ func (h *BaseHandler) respondError(w http.ResponseWriter, err error) {
 			status = http.StatusConflict
 		case apperrors.BusinessRuleError:
 			status = http.StatusUnprocessableEntity
+		case apperrors.AccountLocked, apperrors.RateLimited:
+			status = http.StatusTooManyRequests
 		default:
 			status = http.StatusInternalServerError
 		}
@@ -69,6 +96,9 @@ func (h *BaseHandler) respondError(w http.ResponseWriter, err error) {
 			Code:    string(appErr.Type),
 			Message: appErr.Message,
 		}
+		if appErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
 	} else {
 		// 未知のエラーの場合
 		status = http.StatusInternalServerError
@@ -83,38 +113,193 @@ func (h *BaseHandler) respondError(w http.ResponseWriter, err error) {
 	json.NewEncoder(w).Encode(response)
 }
 
+func (h *BaseHandler) clientIP(r *http.Request) string {
+	if h.config.TrustProxyHeaders {
+		// the leftmost entries are whatever the client sent, so only the ones appended by our own proxies are used
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			entries := strings.Split(strings.Join(forwarded, ","), ",")
+			index := len(entries) - max(h.config.TrustedProxyHops, 1)
+			if index < 0 {
+				index = 0
+			}
+			if ip := strings.TrimSpace(entries[index]); net.ParseIP(ip) != nil {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (h *BaseHandler) getIntQuery(r *http.Request, key string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, apperrors.NewValidationError("invalid "+key, err)
+	}
+	return n, nil
+}
+
+// getUUIDQuery は省略可能な UUID のクエリパラメータを読み取ります
+func (h *BaseHandler) getUUIDQuery(r *http.Request, key string) (*uuid.UUID, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid "+key, err)
+	}
+	return &id, nil
+}
+
+// getUUIDListQuery は "a,b" のようにカンマ区切りの UUID のクエリパラメータを読み取ります
+func (h *BaseHandler) getUUIDListQuery(r *http.Request, key string) ([]uuid.UUID, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ",")
+	ids := make([]uuid.UUID, len(parts))
+	for i, part := range parts {
+		id, err := uuid.Parse(strings.TrimSpace(part))
+		if err != nil {
+			return nil, apperrors.NewValidationError("invalid "+key, err)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// getTimeQuery は省略可能な RFC3339 形式の日時のクエリパラメータを読み取ります
+func (h *BaseHandler) getTimeQuery(r *http.Request, key string) (*time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, apperrors.NewValidationError(key+" must be an RFC3339 timestamp", err)
+	}
+	return &t, nil
+}
+
 func (h *BaseHandler) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			h.respondError(w, apperrors.NewUnauthorizedError("authorization header is required", nil))
-			return
-		}
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
+		switch {
+		case authHeader != "" && tokenString == authHeader:
 			h.respondError(w, apperrors.NewUnauthorizedError("invalid authorization header format", nil))
 			return
+		case authHeader == "":
+			// cookies are sent by the browser automatically, so they need csrf protection
+			tokenString = h.cookieValue(r, accessTokenCookieName)
+			if tokenString == "" {
+				h.respondError(w, apperrors.NewUnauthorizedError("authorization header is required", nil))
+				return
+			}
+			if err := h.checkCSRF(r); err != nil {
+				h.respondError(w, err)
+				return
+			}
 		}
-		claims := &Claims{}
-		_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
+		authenticated, err := h.authUseCase.Authenticate(r.Context(), &input.AuthenticateInput{Token: tokenString})
 		if err != nil {
-			h.respondError(w, apperrors.NewUnauthorizedError("invalid token", err))
+			h.respondError(w, err)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), userContextKey, claims.Email)
+		ctx := context.WithValue(r.Context(), userContextKey, authenticated)
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	})
 }
 
-func (h *BaseHandler) getUserEmail(r *http.Request) string {
-	email, ok := r.Context().Value(userContextKey).(string)
+// sessionOnlyMiddleware は authMiddleware の後に使い、API キーでの認証を拒否します。
+// アカウントや認証情報を変更するエンドポイントはログインしたセッションからのみ利用できます
+func (h *BaseHandler) sessionOnlyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated := h.getAuthenticated(r)
+		if authenticated == nil || authenticated.APIKeyID != nil {
+			h.respondError(w, apperrors.NewPermissionDeniedError("this endpoint cannot be used with an api key", nil))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// noImpersonationMiddleware は authMiddleware の後に使い、管理者によるなりすましを拒否します。
+// パスワードや認証情報など、本人しか変更してはいけない操作に使います
+func (h *BaseHandler) noImpersonationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated := h.getAuthenticated(r)
+		if authenticated == nil || authenticated.ImpersonatorID != nil {
+			h.respondError(w, apperrors.NewPermissionDeniedError("this endpoint cannot be used while impersonating", nil))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// verifiedEmailMiddleware は authMiddleware の後に使い、設定に応じて未確認のアカウントを拒否します
+func (h *BaseHandler) verifiedEmailMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.config.RequireVerifiedEmail {
+			authenticated := h.getAuthenticated(r)
+			if authenticated == nil || authenticated.User.EmailVerifiedAt == nil {
+				h.respondError(w, apperrors.NewPermissionDeniedError("email address is not verified", nil))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission は authMiddleware の後に使い、ロールが指定した権限をすべて持つ場合のみ次のハンドラーを呼び出します
+func (h *BaseHandler) RequirePermission(permissions ...domain.Permission) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authenticated := h.getAuthenticated(r)
+			if authenticated == nil {
+				h.respondError(w, apperrors.NewUnauthorizedError("authentication is required", nil))
+				return
+			}
+			for _, permission := range permissions {
+				if !authenticated.HasPermission(permission) {
+					h.respondError(w, apperrors.NewPermissionDeniedError("permission denied", nil))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *BaseHandler) getAuthenticated(r *http.Request) *output.AuthenticatedOutput {
+	authenticated, ok := r.Context().Value(userContextKey).(*output.AuthenticatedOutput)
 	if !ok {
-		return ""
+		return nil
 	}
-	return email
-}
\ No newline at end of file
+	return authenticated
+}
+
+// getCurrentUser は authMiddleware が読み込んだリクエストのユーザーを返します
+func (h *BaseHandler) getCurrentUser(r *http.Request) *output.UserOutput {
+	authenticated := h.getAuthenticated(r)
+	if authenticated == nil {
+		return nil
+	}
+	return &authenticated.User
+}
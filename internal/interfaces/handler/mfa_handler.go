@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type MFAHandler interface {
+	RegisterMFAHandlers(r *mux.Router)
+	Enroll(w http.ResponseWriter, r *http.Request)
+	Confirm(w http.ResponseWriter, r *http.Request)
+	Disable(w http.ResponseWriter, r *http.Request)
+}
+
+type mfaHandler struct {
+	BaseHandler
+	mfaUseCase usecase.MFAUseCase
+}
+
+func NewMFAHandler(base BaseHandler, mfaUseCase usecase.MFAUseCase) MFAHandler {
+	return &mfaHandler{BaseHandler: base, mfaUseCase: mfaUseCase}
+}
+
+func (h *mfaHandler) RegisterMFAHandlers(r *mux.Router) {
+	mfaRouter := r.PathPrefix(constants.MFAPath).Subrouter()
+	mfaRouter.Use(h.authMiddleware, h.sessionOnlyMiddleware, h.noImpersonationMiddleware)
+
+	mfaRouter.HandleFunc("/enroll", h.Enroll).Methods(http.MethodPost, http.MethodOptions)
+	mfaRouter.HandleFunc("/enroll/confirm", h.Confirm).Methods(http.MethodPost, http.MethodOptions)
+	mfaRouter.HandleFunc("/disable", h.Disable).Methods(http.MethodPost, http.MethodOptions)
+}
+
+func (h *mfaHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := h.getCurrentUser(r)
+
+	output, err := h.mfaUseCase.Enroll(ctx, &input.EnrollMFAInput{
+		UserID:    user.ID,
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *mfaHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input input.ConfirmMFAInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.UserID = h.getCurrentUser(r).ID
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	output, err := h.mfaUseCase.Confirm(ctx, &input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *mfaHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input input.DisableMFAInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.UserID = h.getCurrentUser(r).ID
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	if err := h.mfaUseCase.Disable(ctx, &input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
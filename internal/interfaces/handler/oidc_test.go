@@ -0,0 +1,435 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/interfaces/handler"
+	"go-boilerplate/internal/pkg/auth"
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/pkg/oidc"
+	"go-boilerplate/internal/pkg/oidc/oidctest"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/output"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const oidcProviderName = "mock"
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.idp.SetUser(oidctest.User{Subject: "sub-new", Email: "new@example.com", EmailVerified: true, Name: "New User"})
+
+	resp := env.callback(t, env.authorize(t, env.start(t)))
+	authOutput := decodeAuthOutput(t, resp)
+
+	if authOutput.Token == "" || authOutput.RefreshToken == "" {
+		t.Fatal("expected tokens to be issued")
+	}
+	if authOutput.User.Email != "new@example.com" || authOutput.User.EmailVerifiedAt == nil {
+		t.Fatalf("unexpected user: %+v", authOutput.User)
+	}
+	if len(env.store.users) != 1 {
+		t.Fatalf("expected 1 user, got %d", len(env.store.users))
+	}
+	identity := env.store.identity(oidcProviderName, "sub-new")
+	if identity == nil || identity.UserID != authOutput.User.ID {
+		t.Fatalf("expected the identity to be linked to the new user, got %+v", identity)
+	}
+}
+
+func TestOIDCLoginLinksExistingUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	existing := env.store.addUser("linked@example.com")
+	env.idp.SetUser(oidctest.User{Subject: "sub-linked", Email: "linked@example.com", EmailVerified: true})
+
+	authOutput := decodeAuthOutput(t, env.callback(t, env.authorize(t, env.start(t))))
+
+	if authOutput.User.ID != existing.ID {
+		t.Fatalf("expected existing user %s, got %s", existing.ID, authOutput.User.ID)
+	}
+	if len(env.store.users) != 1 {
+		t.Fatalf("expected no new user, got %d users", len(env.store.users))
+	}
+	identity := env.store.identity(oidcProviderName, "sub-linked")
+	if identity == nil || identity.UserID != existing.ID {
+		t.Fatalf("expected the identity to be linked to the existing user, got %+v", identity)
+	}
+
+	// the second login goes through the stored identity
+	again := decodeAuthOutput(t, env.callback(t, env.authorize(t, env.start(t))))
+	if again.User.ID != existing.ID || len(env.store.identities) != 1 {
+		t.Fatalf("expected the same user and identity, got %s and %d identities", again.User.ID, len(env.store.identities))
+	}
+}
+
+func TestOIDCLoginDoesNotLinkUnverifiedEmail(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	env.store.addUser("taken@example.com")
+	env.idp.SetUser(oidctest.User{Subject: "sub-unverified", Email: "taken@example.com", EmailVerified: false})
+
+	resp := env.callback(t, env.authorize(t, env.start(t)))
+
+	expectStatus(t, resp, http.StatusConflict)
+	if len(env.store.identities) != 0 {
+		t.Fatal("expected no identity to be linked")
+	}
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	callbackURL := env.authorize(t, env.start(t))
+
+	query := callbackURL.Query()
+	query.Set("state", "forged-state")
+	callbackURL.RawQuery = query.Encode()
+
+	expectStatus(t, env.callback(t, callbackURL), http.StatusUnauthorized)
+	env.expectNoUsers(t)
+}
+
+func TestOIDCCallbackRejectsPKCEVerifierMismatch(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	// the code is bound to the first login's challenge
+	first := env.authorize(t, env.start(t))
+	// starting again replaces the state cookie and with it the verifier
+	second := env.authorize(t, env.start(t))
+
+	query := second.Query()
+	query.Set("code", first.Query().Get("code"))
+	second.RawQuery = query.Encode()
+
+	expectStatus(t, env.callback(t, second), http.StatusUnauthorized)
+	env.expectNoUsers(t)
+}
+
+func TestOIDCCallbackRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(idp *oidctest.Server)
+	}{
+		{
+			name: "nonce mismatch",
+			setup: func(idp *oidctest.Server) {
+				idp.ModifyIDToken(func(claims *oidc.IDTokenClaims) { claims.Nonce = "other-nonce" })
+			},
+		},
+		{
+			name: "wrong issuer",
+			setup: func(idp *oidctest.Server) {
+				idp.ModifyIDToken(func(claims *oidc.IDTokenClaims) { claims.Issuer = "https://attacker.example.com" })
+			},
+		},
+		{
+			name: "wrong audience",
+			setup: func(idp *oidctest.Server) {
+				idp.ModifyIDToken(func(claims *oidc.IDTokenClaims) { claims.Audience = []string{"another-client"} })
+			},
+		},
+		{
+			name: "bad signature",
+			setup: func(idp *oidctest.Server) {
+				idp.SignWithUnpublishedKey()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			tt.setup(env.idp)
+
+			expectStatus(t, env.callback(t, env.authorize(t, env.start(t))), http.StatusUnauthorized)
+			env.expectNoUsers(t)
+		})
+	}
+}
+
+func TestOIDCCallbackRejectsMissingStateCookie(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	callbackURL := env.authorize(t, env.start(t))
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.client.Jar = jar
+
+	expectStatus(t, env.callback(t, callbackURL), http.StatusBadRequest)
+	env.expectNoUsers(t)
+}
+
+// oidcTestEnv はモックの IdP と、それに接続したアプリケーションのサーバーです
+type oidcTestEnv struct {
+	idp    *oidctest.Server
+	app    *httptest.Server
+	client *http.Client
+	store  *memoryStore
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+
+	idp := oidctest.NewServer("client-id", "client-secret")
+	t.Cleanup(idp.Close)
+
+	// the redirect url needs the app address, so the router is attached after the server starts
+	var router http.Handler
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		router.ServeHTTP(w, r)
+	}))
+	t.Cleanup(app.Close)
+
+	redirectURL := app.URL + constants.AuthPath + "/oidc/" + oidcProviderName + "/callback"
+	providers := oidc.Providers{
+		oidcProviderName: oidc.NewProvider(idp.Config(oidcProviderName, redirectURL), idp.Client()),
+	}
+	keyManager, err := auth.NewHMACKeyManager([]byte("oidc-test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := &memoryStore{users: map[uuid.UUID]*dto.UserOutput{}}
+	authUseCase := usecase.NewAuthUseCase(
+		&memoryUserRepository{store: store},
+		&memoryRefreshTokenRepository{},
+		nil,
+		&memorySessionRepository{},
+		nil,
+		nil,
+		nil,
+		&memoryMFARepository{},
+		nil,
+		&memoryUserIdentityRepository{store: store},
+		providers,
+		keyManager,
+		auth.NewPasswordHasher(auth.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}),
+		nil,
+		discardAuditLogger{},
+		nil,
+		usecase.LoginThrottleConfig{},
+	)
+	r := mux.NewRouter()
+	handler.NewAuthHandler(handler.NewBaseHandler(authUseCase, handler.BaseHandlerConfig{}), authUseCase).RegisterAuthHandlers(r)
+	router = r
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Jar: jar,
+		// each hop of the flow is followed by hand so the tests can tamper with it
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &oidcTestEnv{idp: idp, app: app, client: client, store: store}
+}
+
+// start はログインを開始し、IdP の認可エンドポイントの URL を返します
+func (e *oidcTestEnv) start(t *testing.T) *url.URL {
+	t.Helper()
+	resp := e.get(t, e.app.URL+constants.AuthPath+"/oidc/"+oidcProviderName+"/start")
+	expectStatus(t, resp, http.StatusFound)
+	return location(t, resp)
+}
+
+// authorize は IdP で認可し、アプリケーションのコールバックの URL を返します
+func (e *oidcTestEnv) authorize(t *testing.T, authorizationURL *url.URL) *url.URL {
+	t.Helper()
+	resp := e.get(t, authorizationURL.String())
+	expectStatus(t, resp, http.StatusFound)
+	return location(t, resp)
+}
+
+func (e *oidcTestEnv) callback(t *testing.T, callbackURL *url.URL) *http.Response {
+	t.Helper()
+	return e.get(t, callbackURL.String())
+}
+
+func (e *oidcTestEnv) get(t *testing.T, rawURL string) *http.Response {
+	t.Helper()
+	resp, err := e.client.Get(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func (e *oidcTestEnv) expectNoUsers(t *testing.T) {
+	t.Helper()
+	if len(e.store.users) != 0 || len(e.store.identities) != 0 {
+		t.Fatalf("expected no user to be created, got %d users and %d identities", len(e.store.users), len(e.store.identities))
+	}
+}
+
+func location(t *testing.T, resp *http.Response) *url.URL {
+	t.Helper()
+	location, err := resp.Location()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+func expectStatus(t *testing.T, resp *http.Response, status int) {
+	t.Helper()
+	if resp.StatusCode != status {
+		var body handler.ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		t.Fatalf("expected status %d, got %d: %+v", status, resp.StatusCode, body)
+	}
+}
+
+func decodeAuthOutput(t *testing.T, resp *http.Response) *output.AuthOutput {
+	t.Helper()
+	expectStatus(t, resp, http.StatusOK)
+	var authOutput output.AuthOutput
+	if err := json.NewDecoder(resp.Body).Decode(&authOutput); err != nil {
+		t.Fatal(err)
+	}
+	if authOutput.User == nil {
+		t.Fatal("expected the user in the response")
+	}
+	return &authOutput
+}
+
+// memoryStore はテスト用にユーザーと外部アカウントの紐付けをメモリ上に保持します
+type memoryStore struct {
+	mu         sync.Mutex
+	users      map[uuid.UUID]*dto.UserOutput
+	identities []dto.UserIdentityOutput
+}
+
+func (s *memoryStore) addUser(email string) *dto.UserOutput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	user := &dto.UserOutput{ID: uuid.New(), Name: "Existing", Email: email, Role: domain.RoleUser, EmailVerifiedAt: &now}
+	s.users[user.ID] = user
+	return user
+}
+
+func (s *memoryStore) identity(provider string, subject string) *dto.UserIdentityOutput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, identity := range s.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity
+		}
+	}
+	return nil
+}
+
+// memory*Repository は OIDC のログインで使うメソッドだけを実装します。それ以外を呼ぶと埋め込んだ nil のインターフェースで panic します
+type memoryUserRepository struct {
+	repository.UserRepository
+	store *memoryStore
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, input *dto.FindUserByIDInput) (*dto.UserOutput, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	user, ok := r.store.users[input.ID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("user not found", nil)
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, input *dto.FindUserByEmailInput) (*dto.UserOutput, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, user := range r.store.users {
+		if user.Email == input.Email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, apperrors.NewNotFoundError("user not found", nil)
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	user := &dto.UserOutput{ID: uuid.New(), Name: input.Name, Email: input.Email, Password: input.Password, Role: input.Role, Timezone: "UTC"}
+	r.store.users[user.ID] = user
+	copied := *user
+	return &copied, nil
+}
+
+func (r *memoryUserRepository) MarkEmailVerified(ctx context.Context, input *dto.MarkUserEmailVerifiedInput) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	now := time.Now()
+	r.store.users[input.ID].EmailVerifiedAt = &now
+	return nil
+}
+
+type memoryUserIdentityRepository struct {
+	repository.UserIdentityRepository
+	store *memoryStore
+}
+
+func (r *memoryUserIdentityRepository) Find(ctx context.Context, input *dto.FindUserIdentityInput) (*dto.UserIdentityOutput, error) {
+	if identity := r.store.identity(input.Provider, input.Subject); identity != nil {
+		return identity, nil
+	}
+	return nil, apperrors.NewNotFoundError("identity not found", nil)
+}
+
+func (r *memoryUserIdentityRepository) Create(ctx context.Context, input *dto.CreateUserIdentityInput) (*dto.UserIdentityOutput, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	identity := dto.UserIdentityOutput{ID: uuid.New(), UserID: input.UserID, Provider: input.Provider, Subject: input.Subject, Email: input.Email}
+	r.store.identities = append(r.store.identities, identity)
+	return &identity, nil
+}
+
+func (r *memoryUserIdentityRepository) Touch(ctx context.Context, input *dto.TouchUserIdentityInput) error {
+	return nil
+}
+
+type memorySessionRepository struct {
+	repository.SessionRepository
+}
+
+func (r *memorySessionRepository) Create(ctx context.Context, input *dto.CreateSessionInput) (*dto.SessionOutput, error) {
+	return &dto.SessionOutput{ID: input.ID, UserID: input.UserID, LastSeenAt: time.Now(), CreatedAt: time.Now()}, nil
+}
+
+type memoryRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+}
+
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, input *dto.CreateRefreshTokenInput) (*dto.RefreshTokenOutput, error) {
+	return &dto.RefreshTokenOutput{ID: uuid.New(), UserID: input.UserID, FamilyID: input.FamilyID, ExpiresAt: input.ExpiresAt}, nil
+}
+
+type memoryMFARepository struct {
+	repository.MFARepository
+}
+
+func (r *memoryMFARepository) FindByUserID(ctx context.Context, input *dto.FindMFACredentialInput) (*dto.MFACredentialOutput, error) {
+	return nil, apperrors.NewNotFoundError("mfa credential not found", nil)
+}
+
+type discardAuditLogger struct{}
+
+func (discardAuditLogger) Record(ctx context.Context, entry *usecase.AuditEntry) error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"go-boilerplate/internal/pkg/auth"
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase/output"
+	"net/http"
+)
+
+// Cookie セッションモードで使う Cookie とヘッダーの名前です
+const (
+	accessTokenCookieName  = "access_token"
+	refreshTokenCookieName = "refresh_token"
+	csrfTokenCookieName    = "csrf_token"
+	csrfTokenHeaderName    = "X-CSRF-Token"
+)
+
+// respondAuth は認証結果を返します。Cookie セッションモードではトークンを HttpOnly Cookie に保存し、
+// JavaScript から読めないようレスポンスボディからは取り除きます
+func (h *BaseHandler) respondAuth(w http.ResponseWriter, status int, authOutput *output.AuthOutput) {
+	if h.config.CookieSessions && authOutput.Token != "" {
+		if err := h.setSessionCookies(w, authOutput); err != nil {
+			h.respondError(w, err)
+			return
+		}
+		body := *authOutput
+		body.Token = ""
+		body.RefreshToken = ""
+		authOutput = &body
+	}
+	h.respondJSON(w, status, authOutput)
+}
+
+func (h *BaseHandler) setSessionCookies(w http.ResponseWriter, authOutput *output.AuthOutput) error {
+	csrfToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return apperrors.NewInternalError("failed to create csrf token", err)
+	}
+
+	http.SetCookie(w, h.sessionCookie(accessTokenCookieName, authOutput.Token, "/", int(authOutput.ExpiresIn), true))
+	// the refresh token is only ever sent to the auth endpoints
+	http.SetCookie(w, h.sessionCookie(refreshTokenCookieName, authOutput.RefreshToken, constants.AuthPath, auth.REFRESH_TOKEN_EXPIRATION, true))
+	// readable by the spa so it can echo the value back in the csrf header
+	http.SetCookie(w, h.sessionCookie(csrfTokenCookieName, csrfToken, "/", auth.REFRESH_TOKEN_EXPIRATION, false))
+	return nil
+}
+
+func (h *BaseHandler) clearSessionCookies(w http.ResponseWriter) {
+	if !h.config.CookieSessions {
+		return
+	}
+	http.SetCookie(w, h.sessionCookie(accessTokenCookieName, "", "/", -1, true))
+	http.SetCookie(w, h.sessionCookie(refreshTokenCookieName, "", constants.AuthPath, -1, true))
+	http.SetCookie(w, h.sessionCookie(csrfTokenCookieName, "", "/", -1, false))
+}
+
+func (h *BaseHandler) sessionCookie(name string, value string, path string, maxAge int, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: httpOnly,
+		Secure:   h.config.SecureCookies,
+		SameSite: h.config.CookieSameSite,
+	}
+}
+
+// cookieValue は Cookie セッションモードの場合のみ Cookie の値を返します
+func (h *BaseHandler) cookieValue(r *http.Request, name string) string {
+	if !h.config.CookieSessions {
+		return ""
+	}
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// checkCSRF は Cookie で認証された状態変更リクエストについて、
+// ヘッダーの CSRF トークンが Cookie の値と一致するかを確認します (double-submit cookie)
+func (h *BaseHandler) checkCSRF(r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	cookie := h.cookieValue(r, csrfTokenCookieName)
+	header := r.Header.Get(csrfTokenHeaderName)
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+		return apperrors.NewPermissionDeniedError("invalid csrf token", nil)
+	}
+	return nil
+}
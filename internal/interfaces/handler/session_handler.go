@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type SessionHandler interface {
+	RegisterSessionHandlers(r *mux.Router)
+	ListSessions(w http.ResponseWriter, r *http.Request)
+	RevokeSession(w http.ResponseWriter, r *http.Request)
+}
+
+type sessionHandler struct {
+	BaseHandler
+	sessionUseCase usecase.SessionUseCase
+}
+
+func NewSessionHandler(base BaseHandler, sessionUseCase usecase.SessionUseCase) SessionHandler {
+	return &sessionHandler{BaseHandler: base, sessionUseCase: sessionUseCase}
+}
+
+func (h *sessionHandler) RegisterSessionHandlers(r *mux.Router) {
+	sessionRouter := r.PathPrefix(constants.SessionsPath).Subrouter()
+	sessionRouter.Use(h.authMiddleware, h.sessionOnlyMiddleware, h.noImpersonationMiddleware)
+
+	sessionRouter.HandleFunc("", h.ListSessions).Methods(http.MethodGet, http.MethodOptions)
+	sessionRouter.HandleFunc("/{id}", h.RevokeSession).Methods(http.MethodDelete, http.MethodOptions)
+}
+
+func (h *sessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	authenticated := h.getAuthenticated(r)
+
+	output, err := h.sessionUseCase.ListSessions(ctx, &input.ListSessionsInput{
+		UserID:           authenticated.User.ID,
+		CurrentSessionID: authenticated.SessionID,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *sessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	authenticated := h.getAuthenticated(r)
+
+	sessionID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid session id", err))
+		return
+	}
+
+	if err := h.sessionUseCase.RevokeSession(ctx, &input.RevokeSessionInput{
+		ID:        sessionID,
+		UserID:    authenticated.User.ID,
+		IPAddress: h.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	// signing out the current session behaves like logout
+	if sessionID == authenticated.SessionID {
+		h.clearSessionCookies(w)
+	}
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type TagHandler interface {
+	RegisterTagHandlers(r *mux.Router)
+	ListTags(w http.ResponseWriter, r *http.Request)
+	GetTag(w http.ResponseWriter, r *http.Request)
+	CreateTag(w http.ResponseWriter, r *http.Request)
+	UpdateTag(w http.ResponseWriter, r *http.Request)
+	DeleteTag(w http.ResponseWriter, r *http.Request)
+}
+
+type tagHandler struct {
+	BaseHandler
+	tagUseCase usecase.TagUseCase
+}
+
+func NewTagHandler(base BaseHandler, tagUseCase usecase.TagUseCase) TagHandler {
+	return &tagHandler{BaseHandler: base, tagUseCase: tagUseCase}
+}
+
+// RegisterTagHandlers のタグは todo の分類なので todo と同じ権限で扱います
+func (h *tagHandler) RegisterTagHandlers(r *mux.Router) {
+	tagRouter := r.PathPrefix(constants.TagsPath).Subrouter()
+	tagRouter.Use(h.authMiddleware, h.verifiedEmailMiddleware)
+
+	canRead := h.RequirePermission(domain.PermissionTodosRead)
+	canWrite := h.RequirePermission(domain.PermissionTodosWrite)
+
+	tagRouter.Handle("", canRead(http.HandlerFunc(h.ListTags))).Methods(http.MethodGet, http.MethodOptions)
+	tagRouter.Handle("/{id}", canRead(http.HandlerFunc(h.GetTag))).Methods(http.MethodGet, http.MethodOptions)
+	tagRouter.Handle("", canWrite(http.HandlerFunc(h.CreateTag))).Methods(http.MethodPost, http.MethodOptions)
+	tagRouter.Handle("/{id}", canWrite(http.HandlerFunc(h.UpdateTag))).Methods(http.MethodPut, http.MethodOptions)
+	tagRouter.Handle("/{id}", canWrite(http.HandlerFunc(h.DeleteTag))).Methods(http.MethodDelete, http.MethodOptions)
+}
+
+func (h *tagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	output, err := h.tagUseCase.ListTags(r.Context(), &input.ListTagsInput{
+		UserID: h.getCurrentUser(r).ID,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *tagHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid tag id", err))
+		return
+	}
+
+	output, err := h.tagUseCase.GetTag(r.Context(), &input.GetTagInput{
+		ID:     tagID,
+		UserID: h.getCurrentUser(r).ID,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *tagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var input input.CreateTagInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.UserID = h.getCurrentUser(r).ID
+
+	output, err := h.tagUseCase.CreateTag(r.Context(), &input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, output)
+}
+
+func (h *tagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid tag id", err))
+		return
+	}
+
+	var input input.UpdateTagInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.ID = tagID
+	input.UserID = h.getCurrentUser(r).ID
+
+	output, err := h.tagUseCase.UpdateTag(r.Context(), &input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *tagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tagID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid tag id", err))
+		return
+	}
+
+	if err := h.tagUseCase.DeleteTag(r.Context(), &input.DeleteTagInput{
+		ID:     tagID,
+		UserID: h.getCurrentUser(r).ID,
+	}); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/pkg/constants"
 	apperrors "go-boilerplate/internal/pkg/errors"
 	"go-boilerplate/internal/usecase"
 	"go-boilerplate/internal/usecase/input"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -19,38 +21,82 @@ type TodoHandler interface {
 	CreateTodo(w http.ResponseWriter, r *http.Request)
 	UpdateTodo(w http.ResponseWriter, r *http.Request)
 	DeleteTodo(w http.ResponseWriter, r *http.Request)
+	StartTodo(w http.ResponseWriter, r *http.Request)
+	CompleteTodo(w http.ResponseWriter, r *http.Request)
+	CancelTodo(w http.ResponseWriter, r *http.Request)
+	ReopenTodo(w http.ResponseWriter, r *http.Request)
 }
 type todoHandler struct {
 	BaseHandler
 	todoUseCase usecase.TodoUseCase
-	userUseCase usecase.UserUseCase
 }
 
-func NewTodoHandler(todoUseCase usecase.TodoUseCase, userUseCase usecase.UserUseCase) TodoHandler {
-	return &todoHandler{todoUseCase: todoUseCase, userUseCase: userUseCase}
+func NewTodoHandler(base BaseHandler, todoUseCase usecase.TodoUseCase) TodoHandler {
+	return &todoHandler{BaseHandler: base, todoUseCase: todoUseCase}
 }
 
 func (h *todoHandler) RegisterTodoHandlers(r *mux.Router) {
 	todoRouter := r.PathPrefix(constants.TodosPath).Subrouter()
-	todoRouter.Use(h.authMiddleware)
-
-	todoRouter.HandleFunc("", h.ListTodo).Methods(http.MethodGet, http.MethodOptions)
-	todoRouter.HandleFunc("/{id}", h.GetTodo).Methods(http.MethodGet, http.MethodOptions)
-	todoRouter.HandleFunc("", h.CreateTodo).Methods(http.MethodPost, http.MethodOptions)
-	todoRouter.HandleFunc("/{id}", h.UpdateTodo).Methods(http.MethodPut, http.MethodOptions)
-	todoRouter.HandleFunc("/{id}", h.DeleteTodo).Methods(http.MethodDelete, http.MethodOptions)
+	todoRouter.Use(h.authMiddleware, h.verifiedEmailMiddleware)
+
+	canRead := h.RequirePermission(domain.PermissionTodosRead)
+	canWrite := h.RequirePermission(domain.PermissionTodosWrite)
+
+	todoRouter.Handle("", canRead(http.HandlerFunc(h.ListTodo))).Methods(http.MethodGet, http.MethodOptions)
+	todoRouter.Handle("/{id}", canRead(http.HandlerFunc(h.GetTodo))).Methods(http.MethodGet, http.MethodOptions)
+	todoRouter.Handle("", canWrite(http.HandlerFunc(h.CreateTodo))).Methods(http.MethodPost, http.MethodOptions)
+	todoRouter.Handle("/{id}", canWrite(http.HandlerFunc(h.UpdateTodo))).Methods(http.MethodPut, http.MethodOptions)
+	todoRouter.Handle("/{id}", canWrite(http.HandlerFunc(h.DeleteTodo))).Methods(http.MethodDelete, http.MethodOptions)
+	todoRouter.Handle("/{id}/start", canWrite(http.HandlerFunc(h.StartTodo))).Methods(http.MethodPost, http.MethodOptions)
+	todoRouter.Handle("/{id}/complete", canWrite(http.HandlerFunc(h.CompleteTodo))).Methods(http.MethodPost, http.MethodOptions)
+	todoRouter.Handle("/{id}/cancel", canWrite(http.HandlerFunc(h.CancelTodo))).Methods(http.MethodPost, http.MethodOptions)
+	todoRouter.Handle("/{id}/reopen", canWrite(http.HandlerFunc(h.ReopenTodo))).Methods(http.MethodPost, http.MethodOptions)
 }
 
 func (h *todoHandler) ListTodo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	email := h.getUserEmail(r)
-	user, err := h.userUseCase.GetUserByEmail(ctx, &input.GetUserByEmailInput{Email: email})
+	user := h.getCurrentUser(r)
+
+	query := r.URL.Query()
+
+	limit, err := h.getIntQuery(r, "limit", input.DefaultPerPage)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+	tagIDs, err := h.getUUIDListQuery(r, "tags")
 	if err != nil {
 		h.respondError(w, err)
 		return
 	}
+	listInput := &input.ListTodoInput{
+		UserID:   user.ID,
+		Query:    query.Get("q"),
+		Status:   domain.TodoStatus(query.Get("status")),
+		TagIDs:   tagIDs,
+		TagMatch: input.TagMatch(query.Get("tag_match")),
+		View:     input.TodoView(query.Get("view")),
+		Timezone: user.Timezone,
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+		Limit:    limit,
+	}
+	for _, param := range []struct {
+		key    string
+		target **time.Time
+	}{
+		{"created_from", &listInput.CreatedFrom},
+		{"created_to", &listInput.CreatedTo},
+		{"updated_from", &listInput.UpdatedFrom},
+		{"updated_to", &listInput.UpdatedTo},
+	} {
+		if *param.target, err = h.getTimeQuery(r, param.key); err != nil {
+			h.respondError(w, err)
+			return
+		}
+	}
 
-	output, err := h.todoUseCase.ListTodo(ctx, &input.ListTodoInput{UserID: user.ID})
+	output, err := h.todoUseCase.ListTodo(ctx, listInput)
 	if err != nil {
 		h.respondError(w, err)
 		return
@@ -62,12 +108,7 @@ func (h *todoHandler) ListTodo(w http.ResponseWriter, r *http.Request) {
 func (h *todoHandler) GetTodo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
-	email := h.getUserEmail(r)
-	user, err := h.userUseCase.GetUserByEmail(ctx, &input.GetUserByEmailInput{Email: email})
-	if err != nil {
-		h.respondError(w, err)
-		return
-	}
+	user := h.getCurrentUser(r)
 
 	todoID, err := uuid.Parse(vars["id"])
 	if err != nil {
@@ -96,13 +137,7 @@ func (h *todoHandler) GetTodo(w http.ResponseWriter, r *http.Request) {
 
 func (h *todoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-
-	email := h.getUserEmail(r)
-	user, err := h.userUseCase.GetUserByEmail(ctx, &input.GetUserByEmailInput{Email: email})
-	if err != nil {
-		h.respondError(w, err)
-		return
-	}
+	user := h.getCurrentUser(r)
 
 	var input input.CreateTodoInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
@@ -128,12 +163,7 @@ func (h *todoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 func (h *todoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
-	email := h.getUserEmail(r)
-	user, err := h.userUseCase.GetUserByEmail(ctx, &input.GetUserByEmailInput{Email: email})
-	if err != nil {
-		h.respondError(w, err)
-		return
-	}
+	user := h.getCurrentUser(r)
 
 	todoID, err := uuid.Parse(vars["id"])
 	if err != nil {
@@ -166,12 +196,7 @@ func (h *todoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 func (h *todoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	vars := mux.Vars(r)
-	email := h.getUserEmail(r)
-	user, err := h.userUseCase.GetUserByEmail(ctx, &input.GetUserByEmailInput{Email: email})
-	if err != nil {
-		h.respondError(w, err)
-		return
-	}
+	user := h.getCurrentUser(r)
 
 	todoID, err := uuid.Parse(vars["id"])
 	if err != nil {
@@ -195,4 +220,41 @@ func (h *todoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.respondJSON(w, http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+func (h *todoHandler) StartTodo(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, domain.TodoStatusInProgress)
+}
+
+func (h *todoHandler) CompleteTodo(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, domain.TodoStatusDone)
+}
+
+func (h *todoHandler) CancelTodo(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, domain.TodoStatusCancelled)
+}
+
+func (h *todoHandler) ReopenTodo(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, domain.TodoStatusOpen)
+}
+
+// changeStatus はステータス変更の各エンドポイントに共通の処理です
+func (h *todoHandler) changeStatus(w http.ResponseWriter, r *http.Request, status domain.TodoStatus) {
+	todoID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid todo id", err))
+		return
+	}
+
+	output, err := h.todoUseCase.ChangeTodoStatus(r.Context(), &input.ChangeTodoStatusInput{
+		ID:     todoID,
+		UserID: h.getCurrentUser(r).ID,
+		Status: status,
+	})
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"encoding/json"
+	"go-boilerplate/internal/pkg/constants"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type UserHandler interface {
+	RegisterUserHandlers(r *mux.Router)
+	GetProfile(w http.ResponseWriter, r *http.Request)
+	UpdateProfile(w http.ResponseWriter, r *http.Request)
+	ChangePassword(w http.ResponseWriter, r *http.Request)
+	DeleteAccount(w http.ResponseWriter, r *http.Request)
+}
+
+type userHandler struct {
+	BaseHandler
+	userUseCase usecase.UserUseCase
+}
+
+func NewUserHandler(base BaseHandler, userUseCase usecase.UserUseCase) UserHandler {
+	return &userHandler{BaseHandler: base, userUseCase: userUseCase}
+}
+
+func (h *userHandler) RegisterUserHandlers(r *mux.Router) {
+	meRouter := r.PathPrefix(constants.MePath).Subrouter()
+	meRouter.Use(h.authMiddleware, h.sessionOnlyMiddleware)
+
+	meRouter.HandleFunc("", h.GetProfile).Methods(http.MethodGet, http.MethodOptions)
+	// support staff may look around as the user but not change their credentials
+	meRouter.Handle("", h.noImpersonationMiddleware(http.HandlerFunc(h.UpdateProfile))).Methods(http.MethodPatch, http.MethodOptions)
+	meRouter.Handle("", h.noImpersonationMiddleware(http.HandlerFunc(h.DeleteAccount))).Methods(http.MethodDelete, http.MethodOptions)
+	meRouter.Handle("/password", h.noImpersonationMiddleware(http.HandlerFunc(h.ChangePassword))).Methods(http.MethodPost, http.MethodOptions)
+}
+
+func (h *userHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, h.getCurrentUser(r))
+}
+
+func (h *userHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input input.UpdateProfileInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.UserID = h.getCurrentUser(r).ID
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	output, err := h.userUseCase.UpdateProfile(ctx, &input)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, output)
+}
+
+func (h *userHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input input.ChangePasswordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.UserID = h.getCurrentUser(r).ID
+	input.SessionID = h.getAuthenticated(r).SessionID
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	if err := h.userUseCase.ChangePassword(ctx, &input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
+
+func (h *userHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input input.DeleteAccountInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		h.respondError(w, apperrors.NewValidationError("invalid request body", err))
+		return
+	}
+	input.UserID = h.getCurrentUser(r).ID
+	input.IPAddress = h.clientIP(r)
+	input.UserAgent = r.UserAgent()
+
+	if err := h.userUseCase.DeleteAccount(ctx, &input); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.clearSessionCookies(w)
+	h.respondJSON(w, http.StatusNoContent, nil)
+}
@@ -1,39 +1,102 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"log"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/google/uuid"
 )
 
-const JWT_TOKEN_EXPIRATION = 60 * 60 * 24
+const (
+	ACCESS_TOKEN_EXPIRATION  = 60 * 15
+	REFRESH_TOKEN_EXPIRATION = 60 * 60 * 24 * 30
+
+	PASSWORD_RESET_TOKEN_EXPIRATION     = 60 * 60
+	EMAIL_VERIFICATION_TOKEN_EXPIRATION = 60 * 60 * 24
+	MAGIC_LINK_TOKEN_EXPIRATION         = 60 * 15
+
+	MFA_TOKEN_EXPIRATION           = 60 * 5
+	OIDC_STATE_TOKEN_EXPIRATION    = 60 * 10
+	IMPERSONATION_TOKEN_EXPIRATION = 60 * 10
+)
+
+// token_use クレームの値です。MFA 待ちのトークンをアクセストークンとして使えないようにします
+const (
+	TokenUseAccess    = "access"
+	TokenUseMFA       = "mfa"
+	TokenUseOIDCState = "oidc_state"
+)
 
 type Claims struct {
-	Email string `json:"email"`
+	Email         string       `json:"email"`
+	EmailVerified bool         `json:"email_verified"`
+	Role          string       `json:"role"`
+	SessionID     string       `json:"sid"`
+	TokenUse      string       `json:"token_use"`
+	Actor         *ActorClaims `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+// ActorClaims は RFC 8693 の act クレームです。管理者がなりすましている場合に、実際に操作している管理者を表します
+type ActorClaims struct {
+	Subject string `json:"sub"`
 }
 
-func VerifyPassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+// MFAClaims はパスワード認証後、二要素認証が完了するまでの間に使うトークンのクレームです
+type MFAClaims struct {
+	TokenUse string `json:"token_use"`
+	jwt.RegisteredClaims
 }
 
-func GenerateToken(email string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
-		Email: email,
+// OIDCStateClaims は OpenID Connect の認可リクエストを開始したブラウザに Cookie で持たせる値です。
+// コールバックで state を照合し、nonce と PKCE の code_verifier を取り出します
+type OIDCStateClaims struct {
+	TokenUse     string `json:"token_use"`
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	jwt.RegisteredClaims
+}
+
+// AccessTokenInput の ActorID を指定すると、その管理者によるなりすまし用の短命なトークンになります
+type AccessTokenInput struct {
+	UserID        uuid.UUID
+	Email         string
+	EmailVerified bool
+	Role          string
+	SessionID     uuid.UUID
+	ActorID       *uuid.UUID
+}
+
+// GenerateToken はアクセストークンを署名鍵で署名して発行します
+func (m *KeyManager) GenerateToken(input *AccessTokenInput) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Email:         input.Email,
+		EmailVerified: input.EmailVerified,
+		Role:          input.Role,
+		SessionID:     input.SessionID.String(),
+		TokenUse:      TokenUseAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ID:        uuid.NewString(),
+			Subject:   input.UserID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ACCESS_TOKEN_EXPIRATION * time.Second)),
 		},
-	})
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	}
+	if input.ActorID != nil {
+		claims.Actor = &ActorClaims{Subject: input.ActorID.String()}
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(IMPERSONATION_TOKEN_EXPIRATION * time.Second))
+	}
+	tokenString, err := m.sign(claims)
 	if err != nil {
 		log.Printf("failed to create token: %v", err)
 		return "", err
@@ -41,8 +104,107 @@ func GenerateToken(email string) (string, error) {
 	return tokenString, nil
 }
 
-func ParseToken(tokenString string) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
+// ParseToken はアクセストークンの署名・アルゴリズム・有効期限を検証します
+func (m *KeyManager) ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	if err := m.parse(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != TokenUseAccess {
+		return nil, errors.New("token is not an access token")
+	}
+	return claims, nil
+}
+
+// GenerateMFAToken は二要素認証の完了前に発行する短命なトークンを生成します
+func (m *KeyManager) GenerateMFAToken(userID uuid.UUID) (string, error) {
+	now := time.Now()
+	tokenString, err := m.sign(MFAClaims{
+		TokenUse: TokenUseMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(MFA_TOKEN_EXPIRATION * time.Second)),
+		},
 	})
+	if err != nil {
+		log.Printf("failed to create mfa token: %v", err)
+		return "", err
+	}
+	return tokenString, nil
+}
+
+func (m *KeyManager) ParseMFAToken(tokenString string) (*MFAClaims, error) {
+	claims := &MFAClaims{}
+	if err := m.parse(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != TokenUseMFA {
+		return nil, errors.New("token is not an mfa token")
+	}
+	return claims, nil
+}
+
+// GenerateOIDCStateToken は認可リクエストの state などを署名付きのトークンにします
+func (m *KeyManager) GenerateOIDCStateToken(provider string, state string, nonce string, codeVerifier string) (string, error) {
+	now := time.Now()
+	return m.sign(OIDCStateClaims{
+		TokenUse:     TokenUseOIDCState,
+		Provider:     provider,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(OIDC_STATE_TOKEN_EXPIRATION * time.Second)),
+		},
+	})
+}
+
+func (m *KeyManager) ParseOIDCStateToken(tokenString string) (*OIDCStateClaims, error) {
+	claims := &OIDCStateClaims{}
+	if err := m.parse(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != TokenUseOIDCState {
+		return nil, errors.New("token is not an oidc state token")
+	}
+	return claims, nil
+}
+
+// API キーの先頭に付ける文字列です。JWT と区別し、漏洩時にシークレットスキャナーで検出しやすくします
+const APIKeyPrefix = "gbp_"
+
+// GenerateAPIKey は API キーと、一覧表示で使う識別用のプレフィックスを生成します
+func GenerateAPIKey() (key string, prefix string, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	secret, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + secret, prefix, nil
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// GenerateOpaqueToken はリフレッシュトークンなどに使うランダムな文字列を生成します
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken は DB に保存するためのトークンのハッシュ値を返します
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
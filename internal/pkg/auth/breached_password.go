@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 漏洩済みパスワードのファイルを分割する SHA-1 のプレフィックスの長さです
+const breachPrefixLength = 5
+
+// BreachChecker はパスワードが過去の漏洩データに含まれているかを判定します
+type BreachChecker interface {
+	IsBreached(password string) (bool, error)
+}
+
+// prefixFileBreachChecker は Have I Been Pwned の Pwned Passwords と同じ k-anonymity 形式のファイルを使います。
+// ディレクトリには SHA-1 の先頭 5 文字を名前にしたファイル (例: 5BAA6.txt) を置き、
+// 各行に残りの 35 文字と出現回数を "SUFFIX:COUNT" の形式で書きます。
+// 問い合わせごとに 1 ファイルだけを読むため、全体を読み込まずにオフラインで照合できます
+type prefixFileBreachChecker struct {
+	dir string
+}
+
+func NewPrefixFileBreachChecker(dir string) (BreachChecker, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached password path %s is not a directory", dir)
+	}
+	return &prefixFileBreachChecker{dir: dir}, nil
+}
+
+func (c *prefixFileBreachChecker) IsBreached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachPrefixLength], hash[breachPrefixLength:]
+
+	file, err := os.Open(filepath.Join(c.dir, prefix+".txt"))
+	if err != nil {
+		// a missing range file means no breached password has this prefix
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		candidate, _, _ := strings.Cut(line, ":")
+		if strings.EqualFold(candidate, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,262 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// verificationKey は kid で識別される検証用の鍵です
+type verificationKey struct {
+	id     string
+	public crypto.PublicKey
+	jwk    *JWK
+}
+
+// KeyManager はトークンの署名鍵と、ローテーション中も含めた検証鍵を管理します。
+// 検証時は設定されたアルゴリズム以外で署名されたトークンを拒否します
+type KeyManager struct {
+	method     jwt.SigningMethod
+	signingID  string
+	signingKey interface{}
+	keys       map[string]*verificationKey
+	keyIDs     []string
+}
+
+// JWK は RFC 7517 の公開鍵表現です
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewKeyManagerFromEnv は環境変数から KeyManager を作成します。
+// JWT_PRIVATE_KEY_FILE が設定されていればその鍵 (RS256 / EdDSA) で署名し、
+// JWT_VERIFICATION_KEY_FILES (カンマ区切り) の公開鍵はローテーション前の鍵として検証のみに使います。
+// 鍵ファイルが設定されていない場合は開発用に JWT_SECRET による HS256 で動作します
+func NewKeyManagerFromEnv() (*KeyManager, error) {
+	privateKeyFile := os.Getenv("JWT_PRIVATE_KEY_FILE")
+	if privateKeyFile == "" {
+		log.Printf("JWT_PRIVATE_KEY_FILE is not set, signing tokens with HS256")
+		return NewHMACKeyManager([]byte(os.Getenv("JWT_SECRET")))
+	}
+
+	var verificationKeyFiles []string
+	for _, file := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			verificationKeyFiles = append(verificationKeyFiles, file)
+		}
+	}
+	manager, err := NewKeyManager(privateKeyFile, verificationKeyFiles)
+	if err != nil {
+		return nil, err
+	}
+	if alg := os.Getenv("JWT_SIGNING_ALG"); alg != "" && alg != manager.method.Alg() {
+		return nil, fmt.Errorf("JWT_SIGNING_ALG is %s but the private key is for %s", alg, manager.method.Alg())
+	}
+	return manager, nil
+}
+
+// NewHMACKeyManager は共有鍵による HS256 の KeyManager を作成します。JWKS には鍵を公開しません
+func NewHMACKeyManager(secret []byte) (*KeyManager, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("jwt secret is empty")
+	}
+	sum := sha256.Sum256(secret)
+	id := "hs-" + hex.EncodeToString(sum[:8])
+	return &KeyManager{
+		method:     jwt.SigningMethodHS256,
+		signingID:  id,
+		signingKey: secret,
+		keys:       map[string]*verificationKey{id: {id: id, public: secret}},
+		keyIDs:     []string{id},
+	}, nil
+}
+
+// NewKeyManager は PEM 形式の秘密鍵で署名する KeyManager を作成します。
+// verificationKeyFiles には署名鍵と同じアルゴリズムの公開鍵(または秘密鍵)を指定します
+func NewKeyManager(privateKeyFile string, verificationKeyFiles []string) (*KeyManager, error) {
+	block, err := readPEM(privateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	private, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", privateKeyFile, err)
+	}
+
+	manager := &KeyManager{keys: map[string]*verificationKey{}}
+	switch key := private.(type) {
+	case *rsa.PrivateKey:
+		manager.method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		manager.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("%s: unsupported private key type %T", privateKeyFile, key)
+	}
+	manager.signingKey = private
+
+	signing, err := manager.addVerificationKey(private.(crypto.Signer).Public())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", privateKeyFile, err)
+	}
+	manager.signingID = signing.id
+
+	for _, file := range verificationKeyFiles {
+		block, err := readPEM(file)
+		if err != nil {
+			return nil, err
+		}
+		public, err := parsePublicKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if _, err := manager.addVerificationKey(public); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return manager, nil
+}
+
+// Algorithm は署名と検証に使うアルゴリズムを返します
+func (m *KeyManager) Algorithm() string {
+	return m.method.Alg()
+}
+
+// JWKS は検証用の公開鍵を返します。HS256 の場合は空になります
+func (m *KeyManager) JWKS() *JWKSet {
+	set := &JWKSet{Keys: []JWK{}}
+	for _, id := range m.keyIDs {
+		if jwk := m.keys[id].jwk; jwk != nil {
+			set.Keys = append(set.Keys, *jwk)
+		}
+	}
+	return set
+}
+
+func (m *KeyManager) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(m.method, claims)
+	token.Header["kid"] = m.signingID
+	return token.SignedString(m.signingKey)
+}
+
+func (m *KeyManager) parse(tokenString string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := m.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key.public, nil
+	}, jwt.WithValidMethods([]string{m.method.Alg()}))
+	return err
+}
+
+func (m *KeyManager) addVerificationKey(public crypto.PublicKey) (*verificationKey, error) {
+	jwk, err := m.newJWK(public)
+	if err != nil {
+		return nil, err
+	}
+	key := &verificationKey{id: jwk.KeyID, public: public, jwk: jwk}
+	if _, ok := m.keys[key.id]; !ok {
+		m.keyIDs = append(m.keyIDs, key.id)
+	}
+	m.keys[key.id] = key
+	return key, nil
+}
+
+// newJWK は公開鍵を JWK に変換します。kid には RFC 7638 の JWK Thumbprint を使います
+func (m *KeyManager) newJWK(public crypto.PublicKey) (*JWK, error) {
+	var jwk *JWK
+	var thumbprintInput string
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if m.method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("rsa key cannot be used with %s", m.method.Alg())
+		}
+		jwk = &JWK{
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}
+		thumbprintInput = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	case ed25519.PublicKey:
+		if m.method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("ed25519 key cannot be used with %s", m.method.Alg())
+		}
+		jwk = &JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(key),
+		}
+		thumbprintInput = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, jwk.X)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", public)
+	}
+
+	sum := sha256.Sum256([]byte(thumbprintInput))
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(sum[:])
+	jwk.Use = "sig"
+	jwk.Algorithm = m.method.Alg()
+	return jwk, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", file)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (interface{}, error) {
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// parsePublicKey は公開鍵を読み込みます。秘密鍵が渡された場合は対応する公開鍵を返します
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	private, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+	return signer.Public(), nil
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrPasswordMismatch   = errors.New("password does not match")
+	ErrUnknownHashFormat  = errors.New("unknown password hash format")
+	errInvalidArgon2Param = errors.New("invalid argon2id parameters")
+)
+
+// PasswordHasher はパスワードをアルゴリズムとパラメーターを含む PHC 形式の文字列にハッシュ化します。
+// Verify は一致した場合に、現在の設定でハッシュし直すべきかどうかも返します
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(encodedHash string, password string) (needsRehash bool, err error)
+}
+
+// Argon2Params は argon2id のパラメーターです。Memory の単位は KiB です
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params は OWASP Password Storage Cheat Sheet の推奨値です
+var DefaultArgon2Params = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// argon2Hasher は argon2id でハッシュ化し、移行前の bcrypt のハッシュも検証できるハッシャーです
+type argon2Hasher struct {
+	params Argon2Params
+}
+
+func NewPasswordHasher(params Argon2Params) PasswordHasher {
+	return &argon2Hasher{params: params}
+}
+
+// NewPasswordHasherFromEnv は PASSWORD_ARGON2_MEMORY (KiB)、PASSWORD_ARGON2_ITERATIONS、
+// PASSWORD_ARGON2_PARALLELISM で既定値を上書きしたハッシャーを作成します
+func NewPasswordHasherFromEnv() (PasswordHasher, error) {
+	params := DefaultArgon2Params
+	for _, setting := range []struct {
+		key   string
+		value *uint32
+	}{
+		{"PASSWORD_ARGON2_MEMORY", &params.Memory},
+		{"PASSWORD_ARGON2_ITERATIONS", &params.Iterations},
+	} {
+		if value := os.Getenv(setting.key); value != "" {
+			n, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || n == 0 {
+				return nil, fmt.Errorf("%s must be a positive integer", setting.key)
+			}
+			*setting.value = uint32(n)
+		}
+	}
+	if value := os.Getenv("PASSWORD_ARGON2_PARALLELISM"); value != "" {
+		n, err := strconv.ParseUint(value, 10, 8)
+		if err != nil || n == 0 {
+			return nil, errors.New("PASSWORD_ARGON2_PARALLELISM must be between 1 and 255")
+		}
+		params.Parallelism = uint8(n)
+	}
+	return NewPasswordHasher(params), nil
+}
+
+func (h *argon2Hasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2Hasher) Verify(encodedHash string, password string) (bool, error) {
+	switch {
+	case strings.HasPrefix(encodedHash, "$argon2id$"):
+		params, salt, key, err := decodeArgon2Hash(encodedHash)
+		if err != nil {
+			return false, err
+		}
+		actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(actual, key) != 1 {
+			return false, ErrPasswordMismatch
+		}
+		return params != h.params, nil
+	case strings.HasPrefix(encodedHash, "$2a$"), strings.HasPrefix(encodedHash, "$2b$"), strings.HasPrefix(encodedHash, "$2y$"):
+		if err := bcrypt.CompareHashAndPassword([]byte(encodedHash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, ErrPasswordMismatch
+			}
+			return false, err
+		}
+		// bcrypt hashes are migrated to argon2id on the next successful login
+		return true, nil
+	}
+	return false, ErrUnknownHashFormat
+}
+
+// decodeArgon2Hash は $argon2id$v=19$m=...,t=...,p=...$salt$key 形式の文字列を分解します
+func decodeArgon2Hash(encodedHash string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, errInvalidArgon2Param
+	}
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, errInvalidArgon2Param
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
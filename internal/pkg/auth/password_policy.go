@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicyError はパスワードが要件を満たさない理由です。利用者にそのまま表示できます
+type PasswordPolicyError struct {
+	Reason string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return e.Reason
+}
+
+// PasswordPolicy は新しく設定するパスワードの長さと文字種の要件です。
+// BreachChecker が設定されている場合は漏洩済みのパスワードも拒否します
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	BreachChecker    BreachChecker
+}
+
+// DefaultPasswordPolicy は既存の入力チェックと同じく 8 文字以上 100 文字以下だけを要求します
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength: 8,
+	MaxLength: 100,
+}
+
+// NewPasswordPolicyFromEnv は PASSWORD_MIN_LENGTH、PASSWORD_MAX_LENGTH、PASSWORD_REQUIRE_UPPERCASE、
+// PASSWORD_REQUIRE_LOWERCASE、PASSWORD_REQUIRE_DIGIT、PASSWORD_REQUIRE_SYMBOL で既定値を上書きします。
+// BREACHED_PASSWORDS_DIR が設定されていれば漏洩済みパスワードのチェックを有効にします
+func NewPasswordPolicyFromEnv() (*PasswordPolicy, error) {
+	policy := DefaultPasswordPolicy
+	for _, setting := range []struct {
+		key   string
+		value *int
+	}{
+		{"PASSWORD_MIN_LENGTH", &policy.MinLength},
+		{"PASSWORD_MAX_LENGTH", &policy.MaxLength},
+	} {
+		if value := os.Getenv(setting.key); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%s must be a positive integer", setting.key)
+			}
+			*setting.value = n
+		}
+	}
+	if policy.MinLength > policy.MaxLength {
+		return nil, errors.New("PASSWORD_MIN_LENGTH must not exceed PASSWORD_MAX_LENGTH")
+	}
+	policy.RequireUppercase = os.Getenv("PASSWORD_REQUIRE_UPPERCASE") == "true"
+	policy.RequireLowercase = os.Getenv("PASSWORD_REQUIRE_LOWERCASE") == "true"
+	policy.RequireDigit = os.Getenv("PASSWORD_REQUIRE_DIGIT") == "true"
+	policy.RequireSymbol = os.Getenv("PASSWORD_REQUIRE_SYMBOL") == "true"
+
+	if dir := os.Getenv("BREACHED_PASSWORDS_DIR"); dir != "" {
+		checker, err := NewPrefixFileBreachChecker(dir)
+		if err != nil {
+			return nil, err
+		}
+		policy.BreachChecker = checker
+	}
+	return &policy, nil
+}
+
+// Check はパスワードが要件を満たさない場合に *PasswordPolicyError を返します。
+// それ以外のエラーは漏洩済みパスワードのファイルを読めなかった場合です
+func (p *PasswordPolicy) Check(password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength || length > p.MaxLength {
+		return &PasswordPolicyError{Reason: fmt.Sprintf("password must be between %d and %d characters", p.MinLength, p.MaxLength)}
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUppercase && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return &PasswordPolicyError{Reason: "password must contain " + strings.Join(missing, ", ")}
+	}
+
+	if p.BreachChecker != nil {
+		breached, err := p.BreachChecker.IsBreached(password)
+		if err != nil {
+			return fmt.Errorf("failed to check breached passwords: %w", err)
+		}
+		if breached {
+			return &PasswordPolicyError{Reason: "password has appeared in a data breach, choose a different one"}
+		}
+	}
+	return nil
+}
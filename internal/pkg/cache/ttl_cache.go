@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// TTLCache は有効期限付きのインメモリキャッシュです
+type TTLCache[K comparable, V any] struct {
+	mu        sync.Mutex
+	entries   map[K]entry[V]
+	sweepSize int
+}
+
+func NewTTLCache[K comparable, V any]() *TTLCache[K, V] {
+	return &TTLCache[K, V]{
+		entries:   make(map[K]entry[V]),
+		sweepSize: 1024,
+	}
+}
+
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *TTLCache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 期限切れのエントリが溜まり続けないよう、一定件数を超えたらまとめて削除します
+	if len(c.entries) >= c.sweepSize {
+		c.sweep()
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+func (c *TTLCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+func (c *TTLCache[K, V]) sweep() {
+	now := time.Now()
+	for key, e := range c.entries {
+		if now.After(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) >= c.sweepSize {
+		c.sweepSize *= 2
+	}
+}
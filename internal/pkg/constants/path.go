@@ -2,9 +2,16 @@ package constants
 
 const (
 	APIBasePath = "/api/v1"
+	JWKSPath    = "/.well-known/jwks.json"
 )
 
 const (
-	AuthPath = APIBasePath + "/auth"
-	TodosPath = APIBasePath + "/todos"
-)
\ No newline at end of file
+	AuthPath     = APIBasePath + "/auth"
+	TodosPath    = APIBasePath + "/todos"
+	TagsPath     = APIBasePath + "/tags"
+	AdminPath    = APIBasePath + "/admin"
+	MePath       = APIBasePath + "/me"
+	MFAPath      = AuthPath + "/mfa"
+	TokensPath   = MePath + "/tokens"
+	SessionsPath = MePath + "/sessions"
+)
@@ -0,0 +1,30 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Encode はページの位置を表す値を、クライアントが中身に依存しない不透明な文字列に変換します
+func Encode(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode は Encode で作成した文字列を value に復元します
+func Decode(encoded string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
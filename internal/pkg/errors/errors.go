@@ -1,6 +1,10 @@
 package apperrors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 type ErrorType string
 
@@ -11,6 +15,8 @@ const (
 	AlreadyExists     ErrorType = "ALREADY_EXISTS"
 	Unauthorized      ErrorType = "UNAUTHORIZED"
 	BusinessRuleError ErrorType = "BUSINESS_RULE_ERROR"
+	AccountLocked     ErrorType = "ACCOUNT_LOCKED"
+	RateLimited       ErrorType = "RATE_LIMITED"
 	InternalError     ErrorType = "INTERNAL_ERROR"
 )
 
@@ -18,6 +24,8 @@ type AppError struct {
 	Type    ErrorType
 	Message string
 	Err     error
+	// RetryAfter が設定されている場合、クライアントが再試行できるまでの時間を表します
+	RetryAfter time.Duration
 }
 
 func (e *AppError) Error() string {
@@ -51,6 +59,14 @@ func NewUnauthorizedError(message string, err error) *AppError {
 	}
 }
 
+func NewPermissionDeniedError(message string, err error) *AppError {
+	return &AppError{
+		Type:    PermissionDenied,
+		Message: message,
+		Err:     err,
+	}
+}
+
 func NewAlreadyExistsError(message string, err error) *AppError {
 	return &AppError{
 		Type:    AlreadyExists,
@@ -59,6 +75,30 @@ func NewAlreadyExistsError(message string, err error) *AppError {
 	}
 }
 
+func NewBusinessRuleError(message string, err error) *AppError {
+	return &AppError{
+		Type:    BusinessRuleError,
+		Message: message,
+		Err:     err,
+	}
+}
+
+func NewAccountLockedError(message string, retryAfter time.Duration) *AppError {
+	return &AppError{
+		Type:       AccountLocked,
+		Message:    message,
+		RetryAfter: retryAfter,
+	}
+}
+
+func NewRateLimitedError(message string, retryAfter time.Duration) *AppError {
+	return &AppError{
+		Type:       RateLimited,
+		Message:    message,
+		RetryAfter: retryAfter,
+	}
+}
+
 func NewInternalError(message string, err error) *AppError {
 	return &AppError{
 		Type:    InternalError,
@@ -66,3 +106,12 @@ func NewInternalError(message string, err error) *AppError {
 		Err:     err,
 	}
 }
+
+// Is はエラーが指定した種類の AppError かどうかを判定します
+func Is(err error, errorType ErrorType) bool {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Type == errorType
+	}
+	return false
+}
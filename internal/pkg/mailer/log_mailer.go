@@ -0,0 +1,18 @@
+package mailer
+
+import (
+	"context"
+	"log"
+)
+
+// logMailer はメールを送信せずログに出力します。ローカル開発用です
+type logMailer struct{}
+
+func NewLogMailer() Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(ctx context.Context, message *Message) error {
+	log.Printf("mail to=%s subject=%q\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
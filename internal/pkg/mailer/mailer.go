@@ -0,0 +1,24 @@
+package mailer
+
+import (
+	"context"
+	"os"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// NewMailerFromEnv は MAILER_DIR が設定されていればファイルに、なければログにメールを出力する Mailer を返します
+func NewMailerFromEnv() Mailer {
+	if dir := os.Getenv("MAILER_DIR"); dir != "" {
+		return NewFileMailer(dir)
+	}
+	return NewLogMailer()
+}
@@ -1,5 +1,7 @@
 package pointer
 
+import "time"
+
 // String は文字列をポインタに変換するヘルパー関数です
 func String(s string) *string {
 	return &s
@@ -10,7 +12,12 @@ func Int(i int) *int {
 	return &i
 }
 
+// Time は時刻をポインタに変換するヘルパー関数です
+func Time(t time.Time) *time.Time {
+	return &t
+}
+
 // Bool はブール値をポインタに変換するヘルパー関数です
 func Bool(b bool) *bool {
 	return &b
-}
\ No newline at end of file
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type window struct {
+	count   int
+	resetAt time.Time
+}
+
+// Limiter はキーごとに一定時間内の回数を制限する固定ウィンドウ方式のレートリミッターです。
+// 状態はプロセス内に保持するため、複数インスタンスではインスタンスごとの制限になります
+type Limiter struct {
+	mu      sync.Mutex
+	limit   int
+	period  time.Duration
+	windows map[string]window
+}
+
+func NewLimiter(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		limit:   limit,
+		period:  period,
+		windows: make(map[string]window),
+	}
+}
+
+// Allow は回数を 1 つ消費し、上限を超えた場合は false と次に許可されるまでの時間を返します
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.windows[key]
+	if !ok || now.After(w.resetAt) {
+		if len(l.windows) >= 1024 {
+			l.sweep(now)
+		}
+		w = window{resetAt: now.Add(l.period)}
+	}
+	if w.count >= l.limit {
+		return false, w.resetAt.Sub(now)
+	}
+	w.count++
+	l.windows[key] = w
+	return true, 0
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	for key, w := range l.windows {
+		if now.After(w.resetAt) {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, input *dto.CreateAPIKeyInput) (*dto.APIKeyOutput, error)
+	List(ctx context.Context, input *dto.ListAPIKeysInput) ([]dto.APIKeyOutput, error)
+	FindByHash(ctx context.Context, input *dto.FindAPIKeyByHashInput) (*dto.APIKeyOutput, error)
+	Revoke(ctx context.Context, input *dto.RevokeAPIKeyInput) error
+	Touch(ctx context.Context, input *dto.TouchAPIKeyInput) error
+}
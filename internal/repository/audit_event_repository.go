@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+// AuditEventRepository は監査記録を追記します。記録の更新や削除はできません
+type AuditEventRepository interface {
+	Create(ctx context.Context, input *dto.CreateAuditEventInput) (*dto.AuditEventOutput, error)
+	List(ctx context.Context, input *dto.ListAuditEventsInput) (*dto.AuditEventListOutput, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type LoginThrottleRepository interface {
+	Find(ctx context.Context, input *dto.FindLoginThrottleInput) (*dto.LoginThrottleOutput, error)
+	RecordFailure(ctx context.Context, input *dto.RecordLoginFailureInput) (*dto.LoginThrottleOutput, error)
+	Lock(ctx context.Context, input *dto.LockLoginThrottleInput) error
+	Reset(ctx context.Context, input *dto.ResetLoginThrottleInput) error
+}
+
+type LockoutEventRepository interface {
+	Create(ctx context.Context, input *dto.CreateLockoutEventInput) (*dto.LockoutEventOutput, error)
+	List(ctx context.Context, input *dto.ListLockoutEventsInput) (*dto.LockoutEventListOutput, error)
+	FindByID(ctx context.Context, input *dto.FindLockoutEventByIDInput) (*dto.LockoutEventOutput, error)
+	Clear(ctx context.Context, input *dto.ClearLockoutEventsInput) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type MFARepository interface {
+	FindByUserID(ctx context.Context, input *dto.FindMFACredentialInput) (*dto.MFACredentialOutput, error)
+	Save(ctx context.Context, input *dto.SaveMFACredentialInput) error
+	Confirm(ctx context.Context, input *dto.ConfirmMFACredentialInput) error
+	UseStep(ctx context.Context, input *dto.UseMFAStepInput) error
+	Delete(ctx context.Context, input *dto.DeleteMFACredentialInput) error
+	ReplaceRecoveryCodes(ctx context.Context, input *dto.ReplaceMFARecoveryCodesInput) error
+	ConsumeRecoveryCode(ctx context.Context, input *dto.ConsumeMFARecoveryCodeInput) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository interface {
+	FindByHash(ctx context.Context, input *dto.FindRefreshTokenByHashInput) (*dto.RefreshTokenOutput, error)
+	Create(ctx context.Context, input *dto.CreateRefreshTokenInput) (*dto.RefreshTokenOutput, error)
+	MarkRotated(ctx context.Context, input *dto.MarkRefreshTokenRotatedInput) error
+	ListActiveFamilies(ctx context.Context, input *dto.ListActiveRefreshTokenFamiliesInput) ([]uuid.UUID, error)
+	RevokeFamily(ctx context.Context, input *dto.RevokeRefreshTokenFamilyInput) error
+}
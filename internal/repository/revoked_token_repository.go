@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type RevokedTokenRepository interface {
+	Create(ctx context.Context, input *dto.CreateRevokedTokenInput) error
+	IsRevoked(ctx context.Context, input *dto.IsTokenRevokedInput) (bool, error)
+}
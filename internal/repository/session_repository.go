@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, input *dto.CreateSessionInput) (*dto.SessionOutput, error)
+	ListActive(ctx context.Context, input *dto.ListActiveSessionsInput) ([]dto.SessionOutput, error)
+	FindActive(ctx context.Context, input *dto.FindSessionInput) (*dto.SessionOutput, error)
+	Touch(ctx context.Context, input *dto.TouchSessionInput) error
+}
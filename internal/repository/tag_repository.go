@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type TagRepository interface {
+	Create(ctx context.Context, input *dto.CreateTagInput) (*dto.TagOutput, error)
+	List(ctx context.Context, input *dto.ListTagsInput) ([]dto.TagOutput, error)
+	FindByID(ctx context.Context, input *dto.FindTagInput) (*dto.TagOutput, error)
+	Update(ctx context.Context, input *dto.UpdateTagInput) (*dto.TagOutput, error)
+	Delete(ctx context.Context, input *dto.DeleteTagInput) error
+}
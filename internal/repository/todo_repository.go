@@ -10,5 +10,6 @@ type TodoRepository interface {
 	FindByID(ctx context.Context, input *dto.FindByIDInput) (*dto.TodoOutput, error)
 	Create(ctx context.Context, input *dto.CreateTodoInput) (*dto.TodoOutput, error)
 	Update(ctx context.Context, input *dto.UpdateTodoInput) (*dto.TodoOutput, error)
+	UpdateStatus(ctx context.Context, input *dto.UpdateTodoStatusInput) (*dto.TodoOutput, error)
 	Delete(ctx context.Context, input *dto.DeleteTodoInput) error
-}
\ No newline at end of file
+}
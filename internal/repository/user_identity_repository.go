@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type UserIdentityRepository interface {
+	Find(ctx context.Context, input *dto.FindUserIdentityInput) (*dto.UserIdentityOutput, error)
+	Create(ctx context.Context, input *dto.CreateUserIdentityInput) (*dto.UserIdentityOutput, error)
+	Touch(ctx context.Context, input *dto.TouchUserIdentityInput) error
+}
@@ -6,6 +6,14 @@ import (
 )
 
 type UserRepository interface {
+	FindByID(ctx context.Context, input *dto.FindUserByIDInput) (*dto.UserOutput, error)
 	FindByEmail(ctx context.Context, input *dto.FindUserByEmailInput) (*dto.UserOutput, error)
 	Create(ctx context.Context, input *dto.CreateUserInput) (*dto.UserOutput, error)
+	UpdatePassword(ctx context.Context, input *dto.UpdateUserPasswordInput) error
+	MarkEmailVerified(ctx context.Context, input *dto.MarkUserEmailVerifiedInput) error
+	List(ctx context.Context, input *dto.ListUsersInput) (*dto.UserListOutput, error)
+	SetDisabled(ctx context.Context, input *dto.SetUserDisabledInput) error
+	Update(ctx context.Context, input *dto.UpdateUserInput) (*dto.UserOutput, error)
+	SoftDelete(ctx context.Context, input *dto.DeleteUserInput) error
+	Delete(ctx context.Context, input *dto.DeleteUserInput) error
 }
\ No newline at end of file
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+)
+
+type UserTokenRepository interface {
+	FindByHash(ctx context.Context, input *dto.FindUserTokenByHashInput) (*dto.UserTokenOutput, error)
+	Create(ctx context.Context, input *dto.CreateUserTokenInput) (*dto.UserTokenOutput, error)
+	Consume(ctx context.Context, input *dto.ConsumeUserTokenInput) error
+	Invalidate(ctx context.Context, input *dto.InvalidateUserTokensInput) error
+}
@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/auth"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/pkg/mailer"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase/input"
+	"go-boilerplate/internal/usecase/output"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AdminUseCase interface {
+	ListUsers(ctx context.Context, input *input.ListUsersInput) (*output.UserListOutput, error)
+	GetUser(ctx context.Context, input *input.AdminUserInput) (*output.UserOutput, error)
+	DisableUser(ctx context.Context, input *input.AdminUserInput) (*output.UserOutput, error)
+	EnableUser(ctx context.Context, input *input.AdminUserInput) (*output.UserOutput, error)
+	ForcePasswordReset(ctx context.Context, input *input.AdminUserInput) error
+	DeleteUser(ctx context.Context, input *input.AdminUserInput) error
+	ListLockouts(ctx context.Context, input *input.ListLockoutsInput) (*output.LockoutListOutput, error)
+	ClearLockout(ctx context.Context, input *input.ClearLockoutInput) error
+	ImpersonateUser(ctx context.Context, input *input.ImpersonateUserInput) (*output.ImpersonationOutput, error)
+	ListAuditEvents(ctx context.Context, input *input.ListAuditEventsInput) (*output.AuditEventListOutput, error)
+}
+
+type adminUseCase struct {
+	userRepo          repository.UserRepository
+	refreshTokenRepo  repository.RefreshTokenRepository
+	revokedTokenRepo  repository.RevokedTokenRepository
+	sessionRepo       repository.SessionRepository
+	userTokenRepo     repository.UserTokenRepository
+	loginThrottleRepo repository.LoginThrottleRepository
+	lockoutEventRepo  repository.LockoutEventRepository
+	auditEventRepo    repository.AuditEventRepository
+	keyManager        *auth.KeyManager
+	passwordHasher    auth.PasswordHasher
+	auditLogger       AuditLogger
+	mailer            mailer.Mailer
+}
+
+func NewAdminUseCase(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	sessionRepo repository.SessionRepository,
+	userTokenRepo repository.UserTokenRepository,
+	loginThrottleRepo repository.LoginThrottleRepository,
+	lockoutEventRepo repository.LockoutEventRepository,
+	auditEventRepo repository.AuditEventRepository,
+	keyManager *auth.KeyManager,
+	passwordHasher auth.PasswordHasher,
+	auditLogger AuditLogger,
+	mailer mailer.Mailer,
+) AdminUseCase {
+	return &adminUseCase{
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		revokedTokenRepo:  revokedTokenRepo,
+		sessionRepo:       sessionRepo,
+		userTokenRepo:     userTokenRepo,
+		loginThrottleRepo: loginThrottleRepo,
+		lockoutEventRepo:  lockoutEventRepo,
+		auditEventRepo:    auditEventRepo,
+		keyManager:        keyManager,
+		passwordHasher:    passwordHasher,
+		auditLogger:       auditLogger,
+		mailer:            mailer,
+	}
+}
+
+func (u *adminUseCase) ListUsers(ctx context.Context, input *input.ListUsersInput) (*output.UserListOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	users, err := u.userRepo.List(ctx, &dto.ListUsersInput{
+		Query:  input.Query,
+		Limit:  input.PerPage,
+		Offset: (input.Page - 1) * input.PerPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.NewUserListOutput(users, input.Page, input.PerPage), nil
+}
+
+func (u *adminUseCase) GetUser(ctx context.Context, input *input.AdminUserInput) (*output.UserOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.ConvertUserOutput(user), nil
+}
+
+func (u *adminUseCase) DisableUser(ctx context.Context, input *input.AdminUserInput) (*output.UserOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+	if _, err := u.findOtherUser(ctx, input); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.SetDisabled(ctx, &dto.SetUserDisabledInput{ID: input.ID, Disabled: true}); err != nil {
+		return nil, err
+	}
+	// a disabled user must not keep using tokens issued before
+	if err := revokeUserSessions(ctx, u.refreshTokenRepo, u.revokedTokenRepo, input.ID, uuid.Nil); err != nil {
+		return nil, err
+	}
+	u.recordAdminAction(ctx, domain.AuditActionUserDisable, input)
+
+	return u.GetUser(ctx, input)
+}
+
+func (u *adminUseCase) EnableUser(ctx context.Context, input *input.AdminUserInput) (*output.UserOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	if err := u.userRepo.SetDisabled(ctx, &dto.SetUserDisabledInput{ID: input.ID, Disabled: false}); err != nil {
+		return nil, err
+	}
+	u.recordAdminAction(ctx, domain.AuditActionUserEnable, input)
+
+	return u.GetUser(ctx, input)
+}
+
+func (u *adminUseCase) ForcePasswordReset(ctx context.Context, input *input.AdminUserInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.ID})
+	if err != nil {
+		return err
+	}
+
+	// the current password may be compromised, so it stops working until the user picks a new one
+	password, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return apperrors.NewInternalError("failed to reset password", err)
+	}
+	hashedPassword, err := u.passwordHasher.Hash(password)
+	if err != nil {
+		return apperrors.NewInternalError("failed to hash password", err)
+	}
+	if err := u.userRepo.UpdatePassword(ctx, &dto.UpdateUserPasswordInput{ID: user.ID, Password: hashedPassword}); err != nil {
+		return err
+	}
+
+	if err := revokeUserSessions(ctx, u.refreshTokenRepo, u.revokedTokenRepo, user.ID, uuid.Nil); err != nil {
+		return err
+	}
+	if err := sendPasswordResetEmail(ctx, u.userTokenRepo, u.mailer, user); err != nil {
+		return err
+	}
+	u.recordAdminAction(ctx, domain.AuditActionUserPasswordReset, input)
+	return nil
+}
+
+func (u *adminUseCase) DeleteUser(ctx context.Context, input *input.AdminUserInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+	if _, err := u.findOtherUser(ctx, input); err != nil {
+		return err
+	}
+
+	if err := revokeUserSessions(ctx, u.refreshTokenRepo, u.revokedTokenRepo, input.ID, uuid.Nil); err != nil {
+		return err
+	}
+	if err := u.userRepo.Delete(ctx, &dto.DeleteUserInput{ID: input.ID}); err != nil {
+		return err
+	}
+	u.recordAdminAction(ctx, domain.AuditActionUserDelete, input)
+	return nil
+}
+
+func (u *adminUseCase) ListLockouts(ctx context.Context, input *input.ListLockoutsInput) (*output.LockoutListOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	events, err := u.lockoutEventRepo.List(ctx, &dto.ListLockoutEventsInput{
+		ActiveOnly: input.ActiveOnly,
+		Limit:      input.PerPage,
+		Offset:     (input.Page - 1) * input.PerPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.NewLockoutListOutput(events, input.Page, input.PerPage), nil
+}
+
+func (u *adminUseCase) ClearLockout(ctx context.Context, input *input.ClearLockoutInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	event, err := u.lockoutEventRepo.FindByID(ctx, &dto.FindLockoutEventByIDInput{ID: input.ID})
+	if err != nil {
+		return err
+	}
+
+	if err := u.loginThrottleRepo.Reset(ctx, &dto.ResetLoginThrottleInput{
+		Scope:      event.Scope,
+		Identifier: event.Identifier,
+	}); err != nil {
+		return err
+	}
+	if err := u.lockoutEventRepo.Clear(ctx, &dto.ClearLockoutEventsInput{
+		Scope:      event.Scope,
+		Identifier: event.Identifier,
+	}); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionLockoutClear,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &input.ActorID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata: map[string]string{
+			"lockout_id": event.ID.String(),
+			"scope":      string(event.Scope),
+			"identifier": event.Identifier,
+		},
+	})
+	return nil
+}
+
+func (u *adminUseCase) ListAuditEvents(ctx context.Context, input *input.ListAuditEventsInput) (*output.AuditEventListOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	events, err := u.auditEventRepo.List(ctx, &dto.ListAuditEventsInput{
+		Action:   input.Action,
+		Outcome:  input.Outcome,
+		ActorID:  input.ActorID,
+		TargetID: input.TargetID,
+		From:     input.From,
+		To:       input.To,
+		Limit:    input.PerPage,
+		Offset:   (input.Page - 1) * input.PerPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.NewAuditEventListOutput(events, input.Page, input.PerPage), nil
+}
+
+// ImpersonateUser はサポートのために対象ユーザーとして操作できる短命なトークンを発行します。
+// トークンの act クレームに管理者を記録し、開始は必ず監査ログに残します
+func (u *adminUseCase) ImpersonateUser(ctx context.Context, input *input.ImpersonateUserInput) (*output.ImpersonationOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	entry := &AuditEntry{
+		Action:    domain.AuditActionImpersonationStart,
+		Outcome:   domain.AuditOutcomeFailure,
+		ActorID:   &input.ActorID,
+		TargetID:  &input.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	}
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.ID})
+	if err != nil {
+		return nil, err
+	}
+	if denied := impersonationDenied(user, input.ActorID); denied != nil {
+		if err := u.auditLogger.Record(ctx, entry); err != nil {
+			return nil, err
+		}
+		return nil, denied
+	}
+
+	sessionID, err := u.createImpersonationSession(ctx, user.ID, input)
+	if err != nil {
+		return nil, err
+	}
+	token, err := u.keyManager.GenerateToken(&auth.AccessTokenInput{
+		UserID:        user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          string(user.Role),
+		SessionID:     sessionID,
+		ActorID:       &input.ActorID,
+	})
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to create token", err)
+	}
+
+	// no token is handed out unless the start has been recorded
+	entry.Outcome = domain.AuditOutcomeSuccess
+	entry.Metadata = map[string]string{"session_id": sessionID.String()}
+	if err := u.auditLogger.Record(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &output.ImpersonationOutput{
+		Token:          token,
+		ExpiresIn:      auth.IMPERSONATION_TOKEN_EXPIRATION,
+		User:           *output.ConvertUserOutput(user),
+		ImpersonatorID: input.ActorID,
+	}, nil
+}
+
+// createImpersonationSession はなりすまし用のセッションを作成します。
+// 通常のセッションと同じくリフレッシュトークンのファミリーを持つため、ログアウトや全セッションの失効、セッション一覧の対象になります
+func (u *adminUseCase) createImpersonationSession(ctx context.Context, userID uuid.UUID, input *input.ImpersonateUserInput) (uuid.UUID, error) {
+	session, err := u.sessionRepo.Create(ctx, &dto.CreateSessionInput{
+		ID:             uuid.New(),
+		UserID:         userID,
+		UserAgent:      input.UserAgent,
+		IPAddress:      input.IPAddress,
+		ImpersonatorID: &input.ActorID,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// the family only tracks the session lifetime; its token is never handed out, so it cannot be refreshed
+	refreshToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return uuid.Nil, apperrors.NewInternalError("failed to create session", err)
+	}
+	if _, err := u.refreshTokenRepo.Create(ctx, &dto.CreateRefreshTokenInput{
+		UserID:    userID,
+		FamilyID:  session.ID,
+		TokenHash: auth.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(auth.IMPERSONATION_TOKEN_EXPIRATION * time.Second),
+	}); err != nil {
+		return uuid.Nil, err
+	}
+	return session.ID, nil
+}
+
+// impersonationDenied は自分自身、無効化されたユーザー、他の管理者へのなりすましを拒否します
+func impersonationDenied(user *dto.UserOutput, actorID uuid.UUID) error {
+	if user.ID == actorID {
+		return apperrors.NewValidationError("cannot impersonate your own account", nil)
+	}
+	if user.DisabledAt != nil {
+		return apperrors.NewPermissionDeniedError("cannot impersonate a disabled account", nil)
+	}
+	// impersonating another admin would let support staff act with their privileges
+	if user.Role.HasPermission(domain.PermissionUsersImpersonate) {
+		return apperrors.NewPermissionDeniedError("cannot impersonate an administrator", nil)
+	}
+	return nil
+}
+
+// recordAdminAction は管理者によるユーザー操作の成功を監査ログに残します
+func (u *adminUseCase) recordAdminAction(ctx context.Context, action domain.AuditAction, input *input.AdminUserInput) {
+	entry := &AuditEntry{
+		Action:    action,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &input.ActorID,
+		TargetID:  &input.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	}
+	recordAudit(ctx, u.auditLogger, entry)
+}
+
+// findOtherUser は対象ユーザーを取得します。管理者が自分自身を無効化・削除できないようにします
+func (u *adminUseCase) findOtherUser(ctx context.Context, input *input.AdminUserInput) (*dto.UserOutput, error) {
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.ID})
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == input.ActorID {
+		return nil, apperrors.NewValidationError("cannot perform this action on your own account", nil)
+	}
+	return user, nil
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/auth"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase/input"
+	"go-boilerplate/internal/usecase/output"
+	"time"
+)
+
+// last_used_at の更新間隔です。これより短い間隔の利用では書き込みを省略します
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyUseCase interface {
+	CreateAPIKey(ctx context.Context, input *input.CreateAPIKeyInput) (*output.CreatedAPIKeyOutput, error)
+	ListAPIKeys(ctx context.Context, input *input.ListAPIKeysInput) (*output.APIKeyListOutput, error)
+	RevokeAPIKey(ctx context.Context, input *input.RevokeAPIKeyInput) error
+}
+
+type apiKeyUseCase struct {
+	userRepo    repository.UserRepository
+	apiKeyRepo  repository.APIKeyRepository
+	auditLogger AuditLogger
+}
+
+func NewAPIKeyUseCase(userRepo repository.UserRepository, apiKeyRepo repository.APIKeyRepository, auditLogger AuditLogger) APIKeyUseCase {
+	return &apiKeyUseCase{userRepo: userRepo, apiKeyRepo: apiKeyRepo, auditLogger: auditLogger}
+}
+
+func (u *apiKeyUseCase) CreateAPIKey(ctx context.Context, input *input.CreateAPIKeyInput) (*output.CreatedAPIKeyOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	// a key can never grant more than the owner's role allows
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.UserID})
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range input.Scopes {
+		if !user.Role.HasPermission(scope) {
+			return nil, apperrors.NewPermissionDeniedError("scope "+string(scope)+" is not allowed for your role", nil)
+		}
+	}
+
+	key, prefix, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to create api key", err)
+	}
+	created, err := u.apiKeyRepo.Create(ctx, &dto.CreateAPIKeyInput{
+		UserID:    user.ID,
+		Name:      input.Name,
+		Prefix:    prefix,
+		KeyHash:   auth.HashToken(key),
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionAPIKeyCreate,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"api_key_id": created.ID.String(), "name": created.Name},
+	})
+	return &output.CreatedAPIKeyOutput{
+		APIKeyOutput: *output.NewAPIKeyOutput(created),
+		Key:          key,
+	}, nil
+}
+
+func (u *apiKeyUseCase) ListAPIKeys(ctx context.Context, input *input.ListAPIKeysInput) (*output.APIKeyListOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	keys, err := u.apiKeyRepo.List(ctx, &dto.ListAPIKeysInput{UserID: input.UserID})
+	if err != nil {
+		return nil, err
+	}
+	return output.NewAPIKeyListOutput(keys), nil
+}
+
+func (u *apiKeyUseCase) RevokeAPIKey(ctx context.Context, input *input.RevokeAPIKeyInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	if err := u.apiKeyRepo.Revoke(ctx, &dto.RevokeAPIKeyInput{ID: input.ID, UserID: input.UserID}); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionAPIKeyRevoke,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &input.UserID,
+		TargetID:  &input.UserID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"api_key_id": input.ID.String()},
+	})
+	return nil
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/repository"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// AuditEntry は監査ログに記録する 1 件の操作です。ActorID は操作した人、TargetID は操作されたユーザーです
+type AuditEntry struct {
+	Action    domain.AuditAction
+	Outcome   domain.AuditOutcome
+	ActorID   *uuid.UUID
+	TargetID  *uuid.UUID
+	IPAddress string
+	UserAgent string
+	Metadata  map[string]string
+}
+
+// AuditLogger は認証やアカウント操作を監査ログに記録します
+type AuditLogger interface {
+	Record(ctx context.Context, entry *AuditEntry) error
+}
+
+type auditLogger struct {
+	auditEventRepo repository.AuditEventRepository
+}
+
+func NewAuditLogger(auditEventRepo repository.AuditEventRepository) AuditLogger {
+	return &auditLogger{auditEventRepo: auditEventRepo}
+}
+
+func (l *auditLogger) Record(ctx context.Context, entry *AuditEntry) error {
+	_, err := l.auditEventRepo.Create(ctx, &dto.CreateAuditEventInput{
+		Action:    entry.Action,
+		Outcome:   entry.Outcome,
+		ActorID:   entry.ActorID,
+		TargetID:  entry.TargetID,
+		IPAddress: entry.IPAddress,
+		UserAgent: entry.UserAgent,
+		Metadata:  entry.Metadata,
+	})
+	return err
+}
+
+// recordAudit は監査ログに記録します。記録に失敗しても元の操作は完了しているため、ログに残して処理を続けます
+func recordAudit(ctx context.Context, auditLogger AuditLogger, entry *AuditEntry) {
+	if err := auditLogger.Record(ctx, entry); err != nil {
+		log.Printf("failed to record audit event %s: %v", entry.Action, err)
+	}
+}
@@ -2,65 +2,520 @@ package usecase
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/infrastructure/persistence/dto"
 	"go-boilerplate/internal/pkg/auth"
 	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/pkg/mailer"
+	"go-boilerplate/internal/pkg/oidc"
+	"go-boilerplate/internal/pkg/ratelimit"
 	"go-boilerplate/internal/repository"
 	"go-boilerplate/internal/usecase/input"
 	"go-boilerplate/internal/usecase/output"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 同じメールアドレスに送るマジックリンクの上限です
+const (
+	magicLinkRateLimit  = 3
+	magicLinkRateWindow = 15 * time.Minute
 )
 
 type AuthUseCase interface {
 	Login(ctx context.Context, input *input.LoginInput) (*output.AuthOutput, error)
+	VerifyMFA(ctx context.Context, input *input.VerifyMFAInput) (*output.AuthOutput, error)
+	RequestMagicLink(ctx context.Context, input *input.RequestMagicLinkInput) error
+	ConsumeMagicLink(ctx context.Context, input *input.ConsumeMagicLinkInput) (*output.AuthOutput, error)
+	StartOIDC(ctx context.Context, input *input.StartOIDCInput) (*output.OIDCStartOutput, error)
+	OIDCCallback(ctx context.Context, input *input.OIDCCallbackInput) (*output.AuthOutput, error)
 	RegisterUser(ctx context.Context, input *input.RegisterUserInput) (*output.AuthOutput, error)
-	CheckAuthentication(ctx context.Context, input *input.CheckAuthenticationInput) (*output.AuthOutput, error)
+	RefreshToken(ctx context.Context, input *input.RefreshTokenInput) (*output.AuthOutput, error)
+	CheckAuthentication(ctx context.Context, input *input.CheckAuthenticationInput) (*output.UserOutput, error)
+	Authenticate(ctx context.Context, input *input.AuthenticateInput) (*output.AuthenticatedOutput, error)
+	Logout(ctx context.Context, input *input.LogoutInput) error
+	LogoutAll(ctx context.Context, input *input.LogoutAllInput) error
+	ForgotPassword(ctx context.Context, input *input.ForgotPasswordInput) error
+	ResetPassword(ctx context.Context, input *input.ResetPasswordInput) error
+	VerifyEmail(ctx context.Context, input *input.VerifyEmailInput) (*output.UserOutput, error)
+	ResendVerificationEmail(ctx context.Context, input *input.ResendVerificationEmailInput) error
+	GetJWKS(ctx context.Context) (*output.JWKSOutput, error)
 }
 
 type authUseCase struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	sessionRepo      repository.SessionRepository
+	userTokenRepo    repository.UserTokenRepository
+	mfaRepo          repository.MFARepository
+	apiKeyRepo       repository.APIKeyRepository
+	identityRepo     repository.UserIdentityRepository
+	oidcProviders    oidc.Providers
+	keyManager       *auth.KeyManager
+	passwordHasher   auth.PasswordHasher
+	passwordPolicy   *auth.PasswordPolicy
+	auditLogger      AuditLogger
+	mailer           mailer.Mailer
+	throttle         *loginThrottle
+	magicLinkLimiter *ratelimit.Limiter
+	// dummyPasswordHash は存在しないメールアドレスでも同じ時間をかけて検証するためのハッシュです
+	dummyPasswordHash string
 }
 
-func NewAuthUseCase(userRepo repository.UserRepository) AuthUseCase {
-	return &authUseCase{userRepo: userRepo}
+func NewAuthUseCase(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	sessionRepo repository.SessionRepository,
+	userTokenRepo repository.UserTokenRepository,
+	loginThrottleRepo repository.LoginThrottleRepository,
+	lockoutEventRepo repository.LockoutEventRepository,
+	mfaRepo repository.MFARepository,
+	apiKeyRepo repository.APIKeyRepository,
+	identityRepo repository.UserIdentityRepository,
+	oidcProviders oidc.Providers,
+	keyManager *auth.KeyManager,
+	passwordHasher auth.PasswordHasher,
+	passwordPolicy *auth.PasswordPolicy,
+	auditLogger AuditLogger,
+	mailer mailer.Mailer,
+	throttleConfig LoginThrottleConfig,
+) AuthUseCase {
+	return &authUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		sessionRepo:      sessionRepo,
+		userTokenRepo:    userTokenRepo,
+		mfaRepo:          mfaRepo,
+		apiKeyRepo:       apiKeyRepo,
+		identityRepo:     identityRepo,
+		oidcProviders:    oidcProviders,
+		keyManager:       keyManager,
+		passwordHasher:   passwordHasher,
+		passwordPolicy:   passwordPolicy,
+		auditLogger:      auditLogger,
+		mailer:           mailer,
+		throttle: &loginThrottle{
+			throttleRepo:     loginThrottleRepo,
+			lockoutEventRepo: lockoutEventRepo,
+			config:           throttleConfig,
+		},
+		magicLinkLimiter:  ratelimit.NewLimiter(magicLinkRateLimit, magicLinkRateWindow),
+		dummyPasswordHash: newDummyPasswordHash(passwordHasher),
+	}
 }
 
 func (u *authUseCase) Login(ctx context.Context, input *input.LoginInput) (*output.AuthOutput, error) {
 	if err := input.Validate(); err != nil {
 		return nil, apperrors.NewValidationError("invalid input parameters", err)
 	}
+	// reject locked accounts and addresses before checking the password
+	if err := u.throttle.check(ctx, input.Email, input.IPAddress); err != nil {
+		u.auditLoginFailure(ctx, nil, "password", "locked", input.Email, input.IPAddress, input.UserAgent)
+		return nil, err
+	}
+
 	// find user by email
 	user, err := u.userRepo.FindByEmail(ctx, &dto.FindUserByEmailInput{
 		Email: input.Email,
 	})
 	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			// spend as long as a real check so the response time does not reveal which addresses exist
+			_, _ = u.passwordHasher.Verify(u.dummyPasswordHash, input.Password)
+			return nil, u.loginFailed(ctx, input, nil, err)
+		}
 		return nil, err
 	}
 
 	// verify password
-	if err := auth.VerifyPassword(user.Password, input.Password); err != nil {
-		return nil, apperrors.NewUnauthorizedError("email or password is incorrect", err)
+	needsRehash, err := u.passwordHasher.Verify(user.Password, input.Password)
+	if err != nil {
+		return nil, u.loginFailed(ctx, input, &user.ID, err)
+	}
+	if user.DisabledAt != nil {
+		u.auditLoginFailure(ctx, &user.ID, "password", "disabled", input.Email, input.IPAddress, input.UserAgent)
+		return nil, apperrors.NewPermissionDeniedError("account is disabled", nil)
+	}
+	// the plaintext is only available now, so upgrade old hashes while we have it
+	if needsRehash {
+		u.rehashPassword(ctx, user.ID, input.Password)
 	}
 
-	// create jwt token
-	tokenString, err := auth.GenerateToken(user.Email)
+	// the failure counter is kept until the second factor succeeds so codes cannot be brute forced
+	if challenge, err := u.mfaChallenge(ctx, user); err != nil || challenge != nil {
+		return challenge, err
+	}
+
+	if err := u.throttle.reset(ctx, input.Email); err != nil {
+		return nil, err
+	}
+	return u.startSession(ctx, user, &AuditEntry{
+		Action:    domain.AuditActionLogin,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"method": "password"},
+	})
+}
+
+func (u *authUseCase) VerifyMFA(ctx context.Context, input *input.VerifyMFAInput) (*output.AuthOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	claims, err := u.keyManager.ParseMFAToken(input.MFAToken)
 	if err != nil {
-		return nil, apperrors.NewInternalError("failed to create token", err)
+		return nil, apperrors.NewUnauthorizedError("invalid mfa token", err)
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid mfa token", err)
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid mfa token", err)
+	}
+	// mfa tokens are single use
+	revoked, err := u.revokedTokenRepo.IsRevoked(ctx, &dto.IsTokenRevokedInput{TokenID: tokenID})
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, apperrors.NewUnauthorizedError("mfa token has already been used", nil)
 	}
 
-	userOutput := output.ConvertUserOutput(user)
-	return &output.AuthOutput{
-		Token: tokenString,
-		User:  *userOutput,
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: userID})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, apperrors.NewUnauthorizedError("invalid mfa token", err)
+		}
+		return nil, err
+	}
+	if err := u.throttle.check(ctx, user.Email, input.IPAddress); err != nil {
+		return nil, err
+	}
+	if err := verifyMFACode(ctx, u.mfaRepo, user.ID, input.Code); err != nil {
+		if apperrors.Is(err, apperrors.Unauthorized) {
+			u.auditLoginFailure(ctx, &user.ID, "mfa", "invalid_code", user.Email, input.IPAddress, input.UserAgent)
+			if err := u.throttle.recordFailure(ctx, user.Email, input.IPAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := u.revokedTokenRepo.Create(ctx, &dto.CreateRevokedTokenInput{
+		TokenID:   tokenID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}); err != nil {
+		return nil, err
+	}
+	if err := u.throttle.reset(ctx, user.Email); err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, apperrors.NewPermissionDeniedError("account is disabled", nil)
+	}
+
+	return u.startSession(ctx, user, &AuditEntry{
+		Action:    domain.AuditActionLogin,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"method": "password", "mfa": "true"},
+	})
+}
+
+func (u *authUseCase) RequestMagicLink(ctx context.Context, input *input.RequestMagicLinkInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	// limit every address, registered or not, so the response does not reveal which exist
+	if ok, retryAfter := u.magicLinkLimiter.Allow(strings.ToLower(input.Email)); !ok {
+		u.auditMagicLinkRequest(ctx, nil, domain.AuditOutcomeFailure, "rate_limited", input)
+		return apperrors.NewRateLimitedError("too many login links requested, try again later", retryAfter)
+	}
+
+	user, err := u.userRepo.FindByEmail(ctx, &dto.FindUserByEmailInput{
+		Email: input.Email,
+	})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			u.auditMagicLinkRequest(ctx, nil, domain.AuditOutcomeFailure, "unknown_email", input)
+			return nil
+		}
+		return err
+	}
+	if user.DisabledAt != nil {
+		u.auditMagicLinkRequest(ctx, &user.ID, domain.AuditOutcomeFailure, "disabled", input)
+		return nil
+	}
+
+	// only the latest link stays valid
+	if err := u.userTokenRepo.Invalidate(ctx, &dto.InvalidateUserTokensInput{
+		UserID:  user.ID,
+		Purpose: domain.UserTokenPurposeMagicLink,
+	}); err != nil {
+		return err
+	}
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return apperrors.NewInternalError("failed to create login token", err)
+	}
+	if _, err := u.userTokenRepo.Create(ctx, &dto.CreateUserTokenInput{
+		UserID:    user.ID,
+		Purpose:   domain.UserTokenPurposeMagicLink,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(auth.MAGIC_LINK_TOKEN_EXPIRATION * time.Second),
+	}); err != nil {
+		return err
+	}
+
+	if err := u.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Open the link below to log in. The link can be used once and expires in %d minutes.\n\n%s/auth/magic-link?token=%s",
+			auth.MAGIC_LINK_TOKEN_EXPIRATION/60,
+			os.Getenv("FRONTEND_URL"),
+			token,
+		),
+	}); err != nil {
+		return apperrors.NewInternalError("failed to send login email", err)
+	}
+
+	u.auditMagicLinkRequest(ctx, &user.ID, domain.AuditOutcomeSuccess, "", input)
+	return nil
+}
+
+func (u *authUseCase) ConsumeMagicLink(ctx context.Context, input *input.ConsumeMagicLinkInput) (*output.AuthOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	token, err := u.userTokenRepo.FindByHash(ctx, &dto.FindUserTokenByHashInput{
+		Purpose:   domain.UserTokenPurposeMagicLink,
+		TokenHash: auth.HashToken(input.Token),
+	})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			u.auditLoginFailure(ctx, nil, "magic_link", "invalid_token", "", input.IPAddress, input.UserAgent)
+			return nil, apperrors.NewUnauthorizedError("invalid or expired login link", nil)
+		}
+		return nil, err
+	}
+	if err := u.userTokenRepo.Consume(ctx, &dto.ConsumeUserTokenInput{ID: token.ID}); err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			u.auditLoginFailure(ctx, &token.UserID, "magic_link", "token_already_used", "", input.IPAddress, input.UserAgent)
+			return nil, apperrors.NewUnauthorizedError("invalid or expired login link", nil)
+		}
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: token.UserID})
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		u.auditLoginFailure(ctx, &user.ID, "magic_link", "disabled", user.Email, input.IPAddress, input.UserAgent)
+		return nil, apperrors.NewPermissionDeniedError("account is disabled", nil)
+	}
+	// opening the link proves the user controls the address
+	if user.EmailVerifiedAt == nil {
+		if err := u.userRepo.MarkEmailVerified(ctx, &dto.MarkUserEmailVerifiedInput{ID: user.ID}); err != nil {
+			return nil, err
+		}
+		if user, err = u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: user.ID}); err != nil {
+			return nil, err
+		}
+	}
+
+	if challenge, err := u.mfaChallenge(ctx, user); err != nil || challenge != nil {
+		return challenge, err
+	}
+	return u.startSession(ctx, user, &AuditEntry{
+		Action:    domain.AuditActionLogin,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"method": "magic_link"},
+	})
+}
+
+func (u *authUseCase) StartOIDC(ctx context.Context, input *input.StartOIDCInput) (*output.OIDCStartOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	provider, err := u.oidcProviders.Get(input.Provider)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("identity provider not found", err)
+	}
+
+	// state, nonce and the pkce verifier travel in a signed cookie, never through the provider
+	values := make([]string, 3)
+	for i := range values {
+		if values[i], err = oidc.GenerateRandomString(); err != nil {
+			return nil, apperrors.NewInternalError("failed to start oidc login", err)
+		}
+	}
+	state, nonce, codeVerifier := values[0], values[1], values[2]
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to reach identity provider", err)
+	}
+	stateToken, err := u.keyManager.GenerateOIDCStateToken(provider.Name(), state, nonce, codeVerifier)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to start oidc login", err)
+	}
+
+	return &output.OIDCStartOutput{
+		AuthorizationURL: authorizationURL,
+		StateToken:       stateToken,
+		ExpiresIn:        auth.OIDC_STATE_TOKEN_EXPIRATION,
 	}, nil
 }
 
+func (u *authUseCase) OIDCCallback(ctx context.Context, input *input.OIDCCallbackInput) (*output.AuthOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	// the state must match the one bound to this browser, which also stops login csrf
+	stateClaims, err := u.keyManager.ParseOIDCStateToken(input.StateToken)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid or expired login state", err)
+	}
+	if stateClaims.Provider != input.Provider || subtle.ConstantTimeCompare([]byte(stateClaims.State), []byte(input.State)) != 1 {
+		return nil, apperrors.NewUnauthorizedError("invalid or expired login state", nil)
+	}
+	provider, err := u.oidcProviders.Get(input.Provider)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("identity provider not found", err)
+	}
+
+	claims, err := provider.Exchange(ctx, input.Code, stateClaims.CodeVerifier, stateClaims.Nonce)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("failed to verify identity provider response", err)
+	}
+	user, err := u.findOrCreateOIDCUser(ctx, provider.Name(), claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, apperrors.NewPermissionDeniedError("account is disabled", nil)
+	}
+
+	if challenge, err := u.mfaChallenge(ctx, user); err != nil || challenge != nil {
+		return challenge, err
+	}
+	return u.startSession(ctx, user, &AuditEntry{
+		Action:    domain.AuditActionLogin,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"method": "oidc", "provider": provider.Name()},
+	})
+}
+
+// findOrCreateOIDCUser はプロバイダーのアカウントに紐付くユーザーを返します。
+// 未登録の場合、プロバイダーが確認済みとするメールアドレスの既存ユーザーに紐付けるか、新しいユーザーを作成します
+func (u *authUseCase) findOrCreateOIDCUser(ctx context.Context, provider string, claims *oidc.IDTokenClaims) (*dto.UserOutput, error) {
+	identity, err := u.identityRepo.Find(ctx, &dto.FindUserIdentityInput{Provider: provider, Subject: claims.Subject})
+	if err == nil {
+		if err := u.identityRepo.Touch(ctx, &dto.TouchUserIdentityInput{ID: identity.ID, Email: claims.Email}); err != nil {
+			return nil, err
+		}
+		return u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: identity.UserID})
+	}
+	if !apperrors.Is(err, apperrors.NotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, apperrors.NewValidationError("identity provider did not return an email address", nil)
+	}
+	user, err := u.userRepo.FindByEmail(ctx, &dto.FindUserByEmailInput{Email: claims.Email})
+	switch {
+	case err == nil:
+		// an unverified address could belong to someone else, so never link it to an existing account
+		if !claims.EmailVerified {
+			return nil, apperrors.NewAlreadyExistsError("an account with this email already exists", nil)
+		}
+	case apperrors.Is(err, apperrors.NotFound):
+		user, err = u.createOIDCUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if _, err := u.identityRepo.Create(ctx, &dto.CreateUserIdentityInput{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (u *authUseCase) createOIDCUser(ctx context.Context, claims *oidc.IDTokenClaims) (*dto.UserOutput, error) {
+	// the account has no usable password until the user sets one through the reset flow
+	password, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to create user", err)
+	}
+	hashedPassword, err := u.passwordHasher.Hash(password)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to hash password", err)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = strings.Split(claims.Email, "@")[0]
+	}
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	user, err := u.userRepo.Create(ctx, &dto.CreateUserInput{
+		Name:     name,
+		Email:    claims.Email,
+		Password: hashedPassword,
+		Role:     domain.RoleUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claims.EmailVerified {
+		if err := u.userRepo.MarkEmailVerified(ctx, &dto.MarkUserEmailVerifiedInput{ID: user.ID}); err != nil {
+			return nil, err
+		}
+		return u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: user.ID})
+	}
+	return user, nil
+}
+
 func (u *authUseCase) RegisterUser(ctx context.Context, input *input.RegisterUserInput) (*output.AuthOutput, error) {
 	if err := input.Validate(); err != nil {
 		return nil, apperrors.NewValidationError("invalid input parameters", err)
 	}
+	if err := checkPasswordPolicy(u.passwordPolicy, input.Password); err != nil {
+		return nil, err
+	}
 
 	// hash password
-	hashedPassword, err := auth.HashPassword(input.Password)
+	hashedPassword, err := u.passwordHasher.Hash(input.Password)
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to hash password", err)
 	}
@@ -70,46 +525,718 @@ func (u *authUseCase) RegisterUser(ctx context.Context, input *input.RegisterUse
 		Name:     input.Name,
 		Email:    input.Email,
 		Password: hashedPassword,
+		Role:     domain.RoleUser,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// create jwt token
-	tokenString, err := auth.GenerateToken(user.Email)
+	// the account is usable right away; a failed mail can be retried via the resend endpoint
+	if err := sendVerificationEmail(ctx, u.userTokenRepo, u.mailer, user); err != nil {
+		log.Printf("failed to send verification email: %v", err)
+	}
+
+	return u.startSession(ctx, user, &AuditEntry{
+		Action:    domain.AuditActionSignup,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"method": "password"},
+	})
+}
+
+func (u *authUseCase) RefreshToken(ctx context.Context, input *input.RefreshTokenInput) (*output.AuthOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	// find refresh token by hash
+	token, err := u.refreshTokenRepo.FindByHash(ctx, &dto.FindRefreshTokenByHashInput{
+		TokenHash: auth.HashToken(input.RefreshToken),
+	})
 	if err != nil {
-		return nil, apperrors.NewInternalError("failed to create token", err)
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, apperrors.NewUnauthorizedError("invalid refresh token", nil)
+		}
+		return nil, err
+	}
+	if token.RevokedAt != nil {
+		return nil, apperrors.NewUnauthorizedError("refresh token has been revoked", nil)
+	}
+	// a rotated token presented again means it leaked, so revoke the whole family
+	if token.RotatedAt != nil {
+		return nil, u.revokeReusedFamily(ctx, input, token)
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, apperrors.NewUnauthorizedError("refresh token has expired", nil)
 	}
 
-	userOutput := output.ConvertUserOutput(user)
-	return &output.AuthOutput{
-		Token: tokenString,
-		User:  *userOutput,
-	}, nil
+	// rotate refresh token
+	if err := u.refreshTokenRepo.MarkRotated(ctx, &dto.MarkRefreshTokenRotatedInput{ID: token.ID}); err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, u.revokeReusedFamily(ctx, input, token)
+		}
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: token.UserID})
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, apperrors.NewPermissionDeniedError("account is disabled", nil)
+	}
+
+	authOutput, err := u.issueTokens(ctx, user, token.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionTokenRefresh,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"session_id": token.FamilyID.String()},
+	})
+	return authOutput, nil
 }
 
-func (u *authUseCase) CheckAuthentication(ctx context.Context, input *input.CheckAuthenticationInput) (*output.AuthOutput, error) {
+func (u *authUseCase) CheckAuthentication(ctx context.Context, input *input.CheckAuthenticationInput) (*output.UserOutput, error) {
 	if err := input.Validate(); err != nil {
 		return nil, apperrors.NewValidationError("invalid input parameters", err)
 	}
 
-	// find user by email
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{
+		ID: input.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.ConvertUserOutput(user), nil
+}
+
+func (u *authUseCase) Authenticate(ctx context.Context, input *input.AuthenticateInput) (*output.AuthenticatedOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid token", err)
+	}
+
+	if auth.IsAPIKey(input.Token) {
+		return u.authenticateAPIKey(ctx, input.Token)
+	}
+
+	claims, err := u.keyManager.ParseToken(input.Token)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid token", err)
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid token", err)
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid token", err)
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, apperrors.NewUnauthorizedError("invalid token", err)
+	}
+
+	// check revocation of both the token itself and its session
+	for _, id := range []uuid.UUID{tokenID, sessionID} {
+		revoked, err := u.revokedTokenRepo.IsRevoked(ctx, &dto.IsTokenRevokedInput{TokenID: id})
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, apperrors.NewUnauthorizedError("token has been revoked", nil)
+		}
+	}
+
+	// load the current user so role and verification changes apply without waiting for a new token
+	user, err := u.findActiveUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.sessionRepo.Touch(ctx, &dto.TouchSessionInput{ID: sessionID, Interval: sessionTouchInterval}); err != nil {
+		return nil, err
+	}
+
+	authenticated := &output.AuthenticatedOutput{
+		User:      *user,
+		TokenID:   tokenID,
+		SessionID: sessionID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if claims.Actor != nil {
+		impersonatorID, err := u.authenticateImpersonator(ctx, claims.Actor)
+		if err != nil {
+			return nil, err
+		}
+		authenticated.ImpersonatorID = &impersonatorID
+	}
+	return authenticated, nil
+}
+
+// authenticateImpersonator はなりすましている管理者が、今もなりすましの権限を持っているかを確認します
+func (u *authUseCase) authenticateImpersonator(ctx context.Context, actor *auth.ActorClaims) (uuid.UUID, error) {
+	actorID, err := uuid.Parse(actor.Subject)
+	if err != nil {
+		return uuid.Nil, apperrors.NewUnauthorizedError("invalid token", err)
+	}
+	impersonator, err := u.findActiveUser(ctx, actorID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !impersonator.Role.HasPermission(domain.PermissionUsersImpersonate) {
+		return uuid.Nil, apperrors.NewUnauthorizedError("impersonation is no longer allowed", nil)
+	}
+	return actorID, nil
+}
+
+func (u *authUseCase) authenticateAPIKey(ctx context.Context, token string) (*output.AuthenticatedOutput, error) {
+	key, err := u.apiKeyRepo.FindByHash(ctx, &dto.FindAPIKeyByHashInput{KeyHash: auth.HashToken(token)})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, apperrors.NewUnauthorizedError("invalid api key", nil)
+		}
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, apperrors.NewUnauthorizedError("api key has been revoked", nil)
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, apperrors.NewUnauthorizedError("api key has expired", nil)
+	}
+
+	user, err := u.findActiveUser(ctx, key.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.apiKeyRepo.Touch(ctx, &dto.TouchAPIKeyInput{ID: key.ID, Interval: apiKeyTouchInterval}); err != nil {
+		return nil, err
+	}
+
+	authenticated := &output.AuthenticatedOutput{
+		User:     *user,
+		APIKeyID: &key.ID,
+		Scopes:   key.Scopes,
+	}
+	if key.ExpiresAt != nil {
+		authenticated.ExpiresAt = *key.ExpiresAt
+	}
+	return authenticated, nil
+}
+
+// findActiveUser は認証済みのユーザーを読み込み、削除済みや無効化されたアカウントを拒否します
+func (u *authUseCase) findActiveUser(ctx context.Context, userID uuid.UUID) (*output.UserOutput, error) {
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: userID})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, apperrors.NewUnauthorizedError("user no longer exists", nil)
+		}
+		return nil, err
+	}
+	if user.DisabledAt != nil {
+		return nil, apperrors.NewUnauthorizedError("account is disabled", nil)
+	}
+	return output.ConvertUserOutput(user), nil
+}
+
+func (u *authUseCase) Logout(ctx context.Context, input *input.LogoutInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	if err := u.revokedTokenRepo.Create(ctx, &dto.CreateRevokedTokenInput{
+		TokenID:   input.TokenID,
+		ExpiresAt: input.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+	if err := revokeSession(ctx, u.refreshTokenRepo, u.revokedTokenRepo, input.SessionID); err != nil {
+		return err
+	}
+
+	actorID := &input.UserID
+	// logging out of an impersonated session ends the impersonation
+	if input.ImpersonatorID != nil {
+		actorID = input.ImpersonatorID
+		recordAudit(ctx, u.auditLogger, &AuditEntry{
+			Action:    domain.AuditActionImpersonationStop,
+			Outcome:   domain.AuditOutcomeSuccess,
+			ActorID:   input.ImpersonatorID,
+			TargetID:  &input.UserID,
+			IPAddress: input.IPAddress,
+			UserAgent: input.UserAgent,
+			Metadata:  map[string]string{"session_id": input.SessionID.String()},
+		})
+	}
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionLogout,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   actorID,
+		TargetID:  &input.UserID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"session_id": input.SessionID.String()},
+	})
+	return nil
+}
+
+func (u *authUseCase) LogoutAll(ctx context.Context, input *input.LogoutAllInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{
+		ID: input.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	if err := revokeUserSessions(ctx, u.refreshTokenRepo, u.revokedTokenRepo, user.ID, uuid.Nil); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionLogoutAll,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+	return nil
+}
+
+func (u *authUseCase) ForgotPassword(ctx context.Context, input *input.ForgotPasswordInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	// do not reveal whether the email is registered
 	user, err := u.userRepo.FindByEmail(ctx, &dto.FindUserByEmailInput{
 		Email: input.Email,
 	})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return sendPasswordResetEmail(ctx, u.userTokenRepo, u.mailer, user)
+}
+
+func (u *authUseCase) ResetPassword(ctx context.Context, input *input.ResetPasswordInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+	// check before consuming the token so the user can retry with a better password
+	if err := checkPasswordPolicy(u.passwordPolicy, input.Password); err != nil {
+		return err
+	}
+
+	token, err := u.userTokenRepo.FindByHash(ctx, &dto.FindUserTokenByHashInput{
+		Purpose:   domain.UserTokenPurposePasswordReset,
+		TokenHash: auth.HashToken(input.Token),
+	})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return apperrors.NewValidationError("invalid or expired reset token", nil)
+		}
+		return err
+	}
+	if err := u.userTokenRepo.Consume(ctx, &dto.ConsumeUserTokenInput{ID: token.ID}); err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return apperrors.NewValidationError("invalid or expired reset token", nil)
+		}
+		return err
+	}
+
+	// hash password
+	hashedPassword, err := u.passwordHasher.Hash(input.Password)
+	if err != nil {
+		return apperrors.NewInternalError("failed to hash password", err)
+	}
+	if err := u.userRepo.UpdatePassword(ctx, &dto.UpdateUserPasswordInput{
+		ID:       token.UserID,
+		Password: hashedPassword,
+	}); err != nil {
+		return err
+	}
+	if err := revokeUserSessions(ctx, u.refreshTokenRepo, u.revokedTokenRepo, token.UserID, uuid.Nil); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionPasswordReset,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &token.UserID,
+		TargetID:  &token.UserID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+	return nil
+}
+
+func (u *authUseCase) VerifyEmail(ctx context.Context, input *input.VerifyEmailInput) (*output.UserOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	token, err := u.userTokenRepo.FindByHash(ctx, &dto.FindUserTokenByHashInput{
+		Purpose:   domain.UserTokenPurposeEmailVerification,
+		TokenHash: auth.HashToken(input.Token),
+	})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, apperrors.NewValidationError("invalid or expired verification token", nil)
+		}
+		return nil, err
+	}
+	if err := u.userTokenRepo.Consume(ctx, &dto.ConsumeUserTokenInput{ID: token.ID}); err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, apperrors.NewValidationError("invalid or expired verification token", nil)
+		}
+		return nil, err
+	}
+
+	if err := u.userRepo.MarkEmailVerified(ctx, &dto.MarkUserEmailVerifiedInput{ID: token.UserID}); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: token.UserID})
+	if err != nil {
+		return nil, err
+	}
+	return output.ConvertUserOutput(user), nil
+}
+
+func (u *authUseCase) ResendVerificationEmail(ctx context.Context, input *input.ResendVerificationEmailInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{
+		ID: input.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return apperrors.NewValidationError("email is already verified", nil)
+	}
+
+	return sendVerificationEmail(ctx, u.userTokenRepo, u.mailer, user)
+}
+
+func (u *authUseCase) GetJWKS(ctx context.Context) (*output.JWKSOutput, error) {
+	return &output.JWKSOutput{Keys: u.keyManager.JWKS().Keys}, nil
+}
+
+// mfaChallenge は二要素認証が有効なユーザーに対して MFA 待ちのトークンを返します。無効な場合は nil を返します
+func (u *authUseCase) mfaChallenge(ctx context.Context, user *dto.UserOutput) (*output.AuthOutput, error) {
+	enabled, err := mfaEnabled(ctx, u.mfaRepo, user.ID)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	mfaToken, err := u.keyManager.GenerateMFAToken(user.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to create mfa token", err)
+	}
+	return &output.AuthOutput{
+		ExpiresIn:   auth.MFA_TOKEN_EXPIRATION,
+		MFARequired: true,
+		MFAToken:    mfaToken,
+	}, nil
+}
+
+// rehashPassword は古いアルゴリズムやパラメーターのハッシュを現在の設定で保存し直します。
+// 失敗してもログイン自体は成功しているため、ログに残して次回のログインで再試行します
+func (u *authUseCase) rehashPassword(ctx context.Context, userID uuid.UUID, password string) {
+	hashedPassword, err := u.passwordHasher.Hash(password)
+	if err == nil {
+		err = u.userRepo.UpdatePassword(ctx, &dto.UpdateUserPasswordInput{ID: userID, Password: hashedPassword})
+	}
+	if err != nil {
+		log.Printf("failed to rehash password: %v", err)
+	}
+}
+
+// newDummyPasswordHash は誰も知らないパスワードを現在の設定でハッシュ化します。
+// 実在するユーザーと同じパラメーターで検証させるため、起動時に一度だけ作成します
+func newDummyPasswordHash(passwordHasher auth.PasswordHasher) string {
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("failed to create dummy password: %v", err)
+		return ""
+	}
+	hash, err := passwordHasher.Hash(secret)
+	if err != nil {
+		log.Printf("failed to create dummy password hash: %v", err)
+		return ""
+	}
+	return hash
+}
+
+// loginFailed は失敗を記録し、存在しないメールアドレスでもパスワード誤りと同じエラーを返します
+func (u *authUseCase) loginFailed(ctx context.Context, input *input.LoginInput, userID *uuid.UUID, cause error) error {
+	u.auditLoginFailure(ctx, userID, "password", "invalid_credentials", input.Email, input.IPAddress, input.UserAgent)
+	if err := u.throttle.recordFailure(ctx, input.Email, input.IPAddress); err != nil {
+		return err
+	}
+	return apperrors.NewUnauthorizedError("email or password is incorrect", cause)
+}
+
+// auditLoginFailure はログインの失敗を記録します。userID は存在するアカウントの場合だけ指定します
+func (u *authUseCase) auditLoginFailure(ctx context.Context, userID *uuid.UUID, method string, reason string, email string, ipAddress string, userAgent string) {
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionLogin,
+		Outcome:   domain.AuditOutcomeFailure,
+		TargetID:  userID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Metadata:  map[string]string{"method": method, "reason": reason, "email": email},
+	})
+}
+
+// auditMagicLinkRequest はログインリンクの要求を監査ログに記録します。reason は失敗時のみ指定します
+func (u *authUseCase) auditMagicLinkRequest(ctx context.Context, userID *uuid.UUID, outcome domain.AuditOutcome, reason string, input *input.RequestMagicLinkInput) {
+	metadata := map[string]string{"email": input.Email}
+	if reason != "" {
+		metadata["reason"] = reason
+	}
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionMagicLinkRequest,
+		Outcome:   outcome,
+		TargetID:  userID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  metadata,
+	})
+}
+
+// startSession はログインした端末のセッションを記録してトークンを発行し、entry の操作を監査ログに残します
+func (u *authUseCase) startSession(ctx context.Context, user *dto.UserOutput, entry *AuditEntry) (*output.AuthOutput, error) {
+	session, err := u.sessionRepo.Create(ctx, &dto.CreateSessionInput{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		UserAgent: entry.UserAgent,
+		IPAddress: entry.IPAddress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	authOutput, err := u.issueTokens(ctx, user, session.ID)
 	if err != nil {
 		return nil, err
 	}
 
+	entry.Outcome = domain.AuditOutcomeSuccess
+	entry.ActorID = &user.ID
+	entry.TargetID = &user.ID
+	if entry.Metadata == nil {
+		entry.Metadata = map[string]string{}
+	}
+	entry.Metadata["session_id"] = session.ID.String()
+	recordAudit(ctx, u.auditLogger, entry)
+	return authOutput, nil
+}
+
+// issueTokens はアクセストークンと、指定したファミリーに属する新しいリフレッシュトークンを発行します
+func (u *authUseCase) issueTokens(ctx context.Context, user *dto.UserOutput, familyID uuid.UUID) (*output.AuthOutput, error) {
 	// create jwt token
-	tokenString, err := auth.GenerateToken(user.Email)
+	tokenString, err := u.keyManager.GenerateToken(&auth.AccessTokenInput{
+		UserID:        user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Role:          string(user.Role),
+		SessionID:     familyID,
+	})
 	if err != nil {
 		return nil, apperrors.NewInternalError("failed to create token", err)
 	}
 
+	// create refresh token
+	refreshToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to create refresh token", err)
+	}
+	if _, err := u.refreshTokenRepo.Create(ctx, &dto.CreateRefreshTokenInput{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(auth.REFRESH_TOKEN_EXPIRATION * time.Second),
+	}); err != nil {
+		return nil, err
+	}
+
 	userOutput := output.ConvertUserOutput(user)
 	return &output.AuthOutput{
-		Token: tokenString,
-		User:  *userOutput,
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		ExpiresIn:    auth.ACCESS_TOKEN_EXPIRATION,
+		User:         userOutput,
 	}, nil
-}
\ No newline at end of file
+}
+
+func (u *authUseCase) revokeReusedFamily(ctx context.Context, input *input.RefreshTokenInput, token *dto.RefreshTokenOutput) error {
+	// the session may be in an attacker's hands, so its access tokens are cut off as well
+	if err := revokeSession(ctx, u.refreshTokenRepo, u.revokedTokenRepo, token.FamilyID); err != nil {
+		return err
+	}
+	// whoever presented the token is unknown, so only the owner is recorded as the target
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionTokenRefresh,
+		Outcome:   domain.AuditOutcomeFailure,
+		TargetID:  &token.UserID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"reason": "reuse_detected", "session_id": token.FamilyID.String()},
+	})
+	return apperrors.NewUnauthorizedError("refresh token reuse detected", nil)
+}
+
+// checkPasswordPolicy はパスワードの要件を満たさない理由を ValidationError として返します
+func checkPasswordPolicy(policy *auth.PasswordPolicy, password string) error {
+	err := policy.Check(password)
+	if err == nil {
+		return nil
+	}
+	var policyErr *auth.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return apperrors.NewValidationError(policyErr.Reason, err)
+	}
+	return apperrors.NewInternalError("failed to check password", err)
+}
+
+// revokeSession はセッション(リフレッシュトークンのファミリー)を失効させ、
+// そのセッションで発行済みのアクセストークンも使えないようにします
+func revokeSession(
+	ctx context.Context,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	sessionID uuid.UUID,
+) error {
+	if err := refreshTokenRepo.RevokeFamily(ctx, &dto.RevokeRefreshTokenFamilyInput{FamilyID: sessionID}); err != nil {
+		return err
+	}
+	// access tokens of a revoked family can no longer be refreshed, so the
+	// revocation entry only needs to outlive the last issued access token
+	return revokedTokenRepo.Create(ctx, &dto.CreateRevokedTokenInput{
+		TokenID:   sessionID,
+		ExpiresAt: time.Now().Add(auth.ACCESS_TOKEN_EXPIRATION * time.Second),
+	})
+}
+
+// revokeUserSessions はユーザーの全セッションを失効させます。keepSessionID を指定するとそのセッションだけ残します
+func revokeUserSessions(
+	ctx context.Context,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	userID uuid.UUID,
+	keepSessionID uuid.UUID,
+) error {
+	familyIDs, err := refreshTokenRepo.ListActiveFamilies(ctx, &dto.ListActiveRefreshTokenFamiliesInput{UserID: userID})
+	if err != nil {
+		return err
+	}
+	for _, familyID := range familyIDs {
+		if familyID == keepSessionID {
+			continue
+		}
+		if err := revokeSession(ctx, refreshTokenRepo, revokedTokenRepo, familyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendPasswordResetEmail は古いリセットトークンを無効にし、新しいリセットリンクをメールで送ります
+func sendPasswordResetEmail(
+	ctx context.Context,
+	userTokenRepo repository.UserTokenRepository,
+	sender mailer.Mailer,
+	user *dto.UserOutput,
+) error {
+	// only the latest reset link stays valid
+	if err := userTokenRepo.Invalidate(ctx, &dto.InvalidateUserTokensInput{
+		UserID:  user.ID,
+		Purpose: domain.UserTokenPurposePasswordReset,
+	}); err != nil {
+		return err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return apperrors.NewInternalError("failed to create reset token", err)
+	}
+	if _, err := userTokenRepo.Create(ctx, &dto.CreateUserTokenInput{
+		UserID:    user.ID,
+		Purpose:   domain.UserTokenPurposePasswordReset,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(auth.PASSWORD_RESET_TOKEN_EXPIRATION * time.Second),
+	}); err != nil {
+		return err
+	}
+
+	if err := sender.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Open the link below to reset your password. The link expires in %d minutes.\n\n%s/password/reset?token=%s",
+			auth.PASSWORD_RESET_TOKEN_EXPIRATION/60,
+			os.Getenv("FRONTEND_URL"),
+			token,
+		),
+	}); err != nil {
+		return apperrors.NewInternalError("failed to send reset email", err)
+	}
+	return nil
+}
+
+// sendVerificationEmail は古い確認トークンを無効にし、新しい確認リンクをメールで送ります
+func sendVerificationEmail(
+	ctx context.Context,
+	userTokenRepo repository.UserTokenRepository,
+	sender mailer.Mailer,
+	user *dto.UserOutput,
+) error {
+	// only the latest verification link stays valid
+	if err := userTokenRepo.Invalidate(ctx, &dto.InvalidateUserTokensInput{
+		UserID:  user.ID,
+		Purpose: domain.UserTokenPurposeEmailVerification,
+	}); err != nil {
+		return err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return apperrors.NewInternalError("failed to create verification token", err)
+	}
+	if _, err := userTokenRepo.Create(ctx, &dto.CreateUserTokenInput{
+		UserID:    user.ID,
+		Purpose:   domain.UserTokenPurposeEmailVerification,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(auth.EMAIL_VERIFICATION_TOKEN_EXPIRATION * time.Second),
+	}); err != nil {
+		return err
+	}
+
+	if err := sender.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Open the link below to verify your email address. The link expires in %d hours.\n\n%s/auth/verify?token=%s",
+			auth.EMAIL_VERIFICATION_TOKEN_EXPIRATION/60/60,
+			os.Getenv("FRONTEND_URL"),
+			token,
+		),
+	}); err != nil {
+		return apperrors.NewInternalError("failed to send verification email", err)
+	}
+	return nil
+}
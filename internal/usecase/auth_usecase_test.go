@@ -0,0 +1,320 @@
+package usecase_test
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/auth"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	env := newAuthTestEnv(t)
+	user := env.users.add("reuse@example.com")
+	stolen := env.refreshTokens.issue(user.ID, uuid.New())
+
+	rotated, err := env.authUseCase.RefreshToken(context.Background(), &input.RefreshTokenInput{RefreshToken: stolen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.authUseCase.Authenticate(context.Background(), &input.AuthenticateInput{Token: rotated.Token}); err != nil {
+		t.Fatalf("expected the rotated access token to be accepted, got %v", err)
+	}
+
+	// presenting the already rotated token again means it leaked
+	_, err = env.authUseCase.RefreshToken(context.Background(), &input.RefreshTokenInput{RefreshToken: stolen})
+	if !apperrors.Is(err, apperrors.Unauthorized) {
+		t.Fatalf("expected reuse to be rejected, got %v", err)
+	}
+
+	if _, err := env.authUseCase.Authenticate(context.Background(), &input.AuthenticateInput{Token: rotated.Token}); !apperrors.Is(err, apperrors.Unauthorized) {
+		t.Fatalf("expected the session's access token to be revoked, got %v", err)
+	}
+	if _, err := env.authUseCase.RefreshToken(context.Background(), &input.RefreshTokenInput{RefreshToken: rotated.RefreshToken}); !apperrors.Is(err, apperrors.Unauthorized) {
+		t.Fatalf("expected the newest refresh token of the family to be revoked, got %v", err)
+	}
+	env.audit.expect(t, domain.AuditActionTokenRefresh, domain.AuditOutcomeFailure, "reuse_detected")
+}
+
+func TestRefreshTokenConcurrentRotationRevokesSession(t *testing.T) {
+	env := newAuthTestEnv(t)
+	user := env.users.add("race@example.com")
+	familyID := uuid.New()
+	token := env.refreshTokens.issue(user.ID, familyID)
+	// another request rotates the token between the lookup and the update
+	env.refreshTokens.beforeMarkRotated = func(id uuid.UUID) {
+		now := time.Now()
+		env.refreshTokens.tokens[id].RotatedAt = &now
+	}
+
+	_, err := env.authUseCase.RefreshToken(context.Background(), &input.RefreshTokenInput{RefreshToken: token})
+	if !apperrors.Is(err, apperrors.Unauthorized) {
+		t.Fatalf("expected the second rotation to be rejected, got %v", err)
+	}
+	if !env.refreshTokens.familyRevoked(familyID) {
+		t.Fatal("expected the refresh token family to be revoked")
+	}
+	if !env.revokedTokens.has(familyID) {
+		t.Fatal("expected the session to be added to the revocation list")
+	}
+	env.audit.expect(t, domain.AuditActionTokenRefresh, domain.AuditOutcomeFailure, "reuse_detected")
+}
+
+type authTestEnv struct {
+	authUseCase   usecase.AuthUseCase
+	users         *memoryUserRepository
+	refreshTokens *memoryRefreshTokenRepository
+	revokedTokens *memoryRevokedTokenRepository
+	throttles     *memoryLoginThrottleRepository
+	lockouts      *memoryLockoutEventRepository
+	audit         *recordingAuditLogger
+}
+
+func newAuthTestEnv(t *testing.T) *authTestEnv {
+	t.Helper()
+
+	keyManager, err := auth.NewHMACKeyManager([]byte("usecase-test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &authTestEnv{
+		users:         &memoryUserRepository{users: map[uuid.UUID]*dto.UserOutput{}},
+		refreshTokens: &memoryRefreshTokenRepository{tokens: map[uuid.UUID]*dto.RefreshTokenOutput{}, hashes: map[string]uuid.UUID{}},
+		revokedTokens: &memoryRevokedTokenRepository{tokens: map[uuid.UUID]time.Time{}},
+		throttles:     &memoryLoginThrottleRepository{throttles: map[string]*dto.LoginThrottleOutput{}},
+		lockouts:      &memoryLockoutEventRepository{},
+		audit:         &recordingAuditLogger{},
+	}
+	env.authUseCase = usecase.NewAuthUseCase(
+		env.users,
+		env.refreshTokens,
+		env.revokedTokens,
+		&memorySessionRepository{},
+		nil,
+		env.throttles,
+		env.lockouts,
+		nil,
+		nil,
+		nil,
+		nil,
+		keyManager,
+		testPasswordHasher,
+		nil,
+		env.audit,
+		nil,
+		testThrottleConfig,
+	)
+	return env
+}
+
+// testThrottleConfig は 3 回の失敗で 1 分ロックし、以降は 1 時間まで倍にします
+var testThrottleConfig = usecase.LoginThrottleConfig{
+	AccountThreshold: 3,
+	IPThreshold:      100,
+	BaseLockDuration: time.Minute,
+	MaxLockDuration:  time.Hour,
+	FailureWindow:    15 * time.Minute,
+}
+
+// testPasswordHasher はテストを速くするため、最小限のコストでハッシュ化します
+var testPasswordHasher = auth.NewPasswordHasher(auth.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+
+// memory*Repository はテストで使うメソッドだけを実装します。それ以外を呼ぶと埋め込んだ nil のインターフェースで panic します
+type memoryUserRepository struct {
+	repository.UserRepository
+	mu    sync.Mutex
+	users map[uuid.UUID]*dto.UserOutput
+}
+
+func (r *memoryUserRepository) add(email string) *dto.UserOutput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	password, err := testPasswordHasher.Hash("correct-password")
+	if err != nil {
+		panic(err)
+	}
+	now := time.Now()
+	user := &dto.UserOutput{ID: uuid.New(), Name: "User", Email: email, Password: password, Role: domain.RoleUser, EmailVerifiedAt: &now}
+	r.users[user.ID] = user
+	return user
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, input *dto.FindUserByIDInput) (*dto.UserOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[input.ID]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("user not found", nil)
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *memoryUserRepository) FindByEmail(ctx context.Context, input *dto.FindUserByEmailInput) (*dto.UserOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == input.Email {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, apperrors.NewNotFoundError("user not found", nil)
+}
+
+// memoryRefreshTokenRepository は本物と同じく、ローテーション済みか失効したトークンは MarkRotated で NotFound を返します
+type memoryRefreshTokenRepository struct {
+	repository.RefreshTokenRepository
+	mu                sync.Mutex
+	tokens            map[uuid.UUID]*dto.RefreshTokenOutput
+	hashes            map[string]uuid.UUID
+	beforeMarkRotated func(id uuid.UUID)
+}
+
+// issue はファミリーにリフレッシュトークンを追加し、平文のトークンを返します
+func (r *memoryRefreshTokenRepository) issue(userID uuid.UUID, familyID uuid.UUID) string {
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		panic(err)
+	}
+	if _, err := r.Create(context.Background(), &dto.CreateRefreshTokenInput{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}); err != nil {
+		panic(err)
+	}
+	return token
+}
+
+func (r *memoryRefreshTokenRepository) familyRevoked(familyID uuid.UUID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *memoryRefreshTokenRepository) FindByHash(ctx context.Context, input *dto.FindRefreshTokenByHashInput) (*dto.RefreshTokenOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id, ok := r.hashes[input.TokenHash]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("refresh token not found", nil)
+	}
+	token := *r.tokens[id]
+	return &token, nil
+}
+
+func (r *memoryRefreshTokenRepository) Create(ctx context.Context, input *dto.CreateRefreshTokenInput) (*dto.RefreshTokenOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token := &dto.RefreshTokenOutput{
+		ID:        uuid.New(),
+		UserID:    input.UserID,
+		FamilyID:  input.FamilyID,
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	r.tokens[token.ID] = token
+	r.hashes[input.TokenHash] = token.ID
+	created := *token
+	return &created, nil
+}
+
+func (r *memoryRefreshTokenRepository) MarkRotated(ctx context.Context, input *dto.MarkRefreshTokenRotatedInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.beforeMarkRotated != nil {
+		r.beforeMarkRotated(input.ID)
+	}
+	token, ok := r.tokens[input.ID]
+	if !ok || token.RotatedAt != nil || token.RevokedAt != nil {
+		return apperrors.NewNotFoundError("refresh token not found", nil)
+	}
+	now := time.Now()
+	token.RotatedAt = &now
+	return nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(ctx context.Context, input *dto.RevokeRefreshTokenFamilyInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == input.FamilyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type memoryRevokedTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[uuid.UUID]time.Time
+}
+
+func (r *memoryRevokedTokenRepository) has(id uuid.UUID) bool {
+	revoked, _ := r.IsRevoked(context.Background(), &dto.IsTokenRevokedInput{TokenID: id})
+	return revoked
+}
+
+func (r *memoryRevokedTokenRepository) Create(ctx context.Context, input *dto.CreateRevokedTokenInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens[input.TokenID] = input.ExpiresAt
+	return nil
+}
+
+func (r *memoryRevokedTokenRepository) IsRevoked(ctx context.Context, input *dto.IsTokenRevokedInput) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expiresAt, ok := r.tokens[input.TokenID]
+	return ok && time.Now().Before(expiresAt), nil
+}
+
+type memorySessionRepository struct {
+	repository.SessionRepository
+}
+
+func (r *memorySessionRepository) Touch(ctx context.Context, input *dto.TouchSessionInput) error {
+	return nil
+}
+
+// recordingAuditLogger は記録された監査ログをメモリ上に保持します
+type recordingAuditLogger struct {
+	mu      sync.Mutex
+	entries []usecase.AuditEntry
+}
+
+func (l *recordingAuditLogger) Record(ctx context.Context, entry *usecase.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, *entry)
+	return nil
+}
+
+// expect は action と outcome が一致し、metadata の reason が reason の記録があることを確認します
+func (l *recordingAuditLogger) expect(t *testing.T, action domain.AuditAction, outcome domain.AuditOutcome, reason string) {
+	t.Helper()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range l.entries {
+		if entry.Action == action && entry.Outcome == outcome && entry.Metadata["reason"] == reason {
+			return
+		}
+	}
+	t.Fatalf("expected a %s %s audit entry with reason %q, got %+v", action, outcome, reason, l.entries)
+}
@@ -0,0 +1,130 @@
+package input
+
+import (
+	"errors"
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+type ListUsersInput struct {
+	Query   string `json:"query"`
+	Page    int    `json:"page" validate:"min=1"`
+	PerPage int    `json:"per_page" validate:"min=1,max=100"`
+}
+
+func (i *ListUsersInput) Validate() error {
+	if i.Page < 1 {
+		return errors.New("page must be greater than 0")
+	}
+	if i.PerPage < 1 || i.PerPage > MaxPerPage {
+		return errors.New("per_page must be between 1 and 100")
+	}
+	if len(i.Query) > 100 {
+		return errors.New("query must be less than 100 characters")
+	}
+	return nil
+}
+
+type AdminUserInput struct {
+	ID        uuid.UUID `json:"id" validate:"required"`
+	ActorID   uuid.UUID `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *AdminUserInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.ActorID == uuid.Nil {
+		return errors.New("actor_id is required")
+	}
+	return nil
+}
+
+type ImpersonateUserInput struct {
+	ID        uuid.UUID `json:"id" validate:"required"`
+	ActorID   uuid.UUID `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *ImpersonateUserInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.ActorID == uuid.Nil {
+		return errors.New("actor_id is required")
+	}
+	return nil
+}
+
+type ListLockoutsInput struct {
+	ActiveOnly bool `json:"active_only"`
+	Page       int  `json:"page" validate:"min=1"`
+	PerPage    int  `json:"per_page" validate:"min=1,max=100"`
+}
+
+func (i *ListLockoutsInput) Validate() error {
+	if i.Page < 1 {
+		return errors.New("page must be greater than 0")
+	}
+	if i.PerPage < 1 || i.PerPage > MaxPerPage {
+		return errors.New("per_page must be between 1 and 100")
+	}
+	return nil
+}
+
+type ListAuditEventsInput struct {
+	Action   domain.AuditAction  `json:"action"`
+	Outcome  domain.AuditOutcome `json:"outcome"`
+	ActorID  *uuid.UUID          `json:"actor_id"`
+	TargetID *uuid.UUID          `json:"target_id"`
+	From     *time.Time          `json:"from"`
+	To       *time.Time          `json:"to"`
+	Page     int                 `json:"page" validate:"min=1"`
+	PerPage  int                 `json:"per_page" validate:"min=1,max=100"`
+}
+
+func (i *ListAuditEventsInput) Validate() error {
+	if i.Action != "" && !i.Action.IsValid() {
+		return errors.New("action is invalid")
+	}
+	if i.Outcome != "" && !i.Outcome.IsValid() {
+		return errors.New("outcome is invalid")
+	}
+	if i.From != nil && i.To != nil && i.From.After(*i.To) {
+		return errors.New("from must be before to")
+	}
+	if i.Page < 1 {
+		return errors.New("page must be greater than 0")
+	}
+	if i.PerPage < 1 || i.PerPage > MaxPerPage {
+		return errors.New("per_page must be between 1 and 100")
+	}
+	return nil
+}
+
+type ClearLockoutInput struct {
+	ID        uuid.UUID `json:"id" validate:"required"`
+	ActorID   uuid.UUID `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *ClearLockoutInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.ActorID == uuid.Nil {
+		return errors.New("actor_id is required")
+	}
+	return nil
+}
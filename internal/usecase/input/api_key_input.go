@@ -0,0 +1,67 @@
+package input
+
+import (
+	"errors"
+	"go-boilerplate/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateAPIKeyInput struct {
+	UserID    uuid.UUID           `json:"-"`
+	Name      string              `json:"name" validate:"required,min=1,max=100"`
+	Scopes    []domain.Permission `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time          `json:"expires_at"`
+	IPAddress string              `json:"-"`
+	UserAgent string              `json:"-"`
+}
+
+func (i *CreateAPIKeyInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	if i.Name == "" || len(i.Name) > 100 {
+		return errors.New("name must be between 1 and 100 characters")
+	}
+	if len(i.Scopes) == 0 {
+		return errors.New("scopes is required")
+	}
+	for _, scope := range i.Scopes {
+		if !scope.IsValid() {
+			return errors.New("scope " + string(scope) + " is invalid")
+		}
+	}
+	if i.ExpiresAt != nil && !i.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
+
+type ListAPIKeysInput struct {
+	UserID uuid.UUID `json:"-"`
+}
+
+func (i *ListAPIKeysInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+type RevokeAPIKeyInput struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *RevokeAPIKeyInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
@@ -1,10 +1,18 @@
 package input
 
-import "errors"
+import (
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type LoginInput struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=100"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,min=8,max=100"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 func (i *LoginInput) Validate() error {
@@ -18,9 +26,11 @@ func (i *LoginInput) Validate() error {
 }
 
 type RegisterUserInput struct {
-	Name     string `json:"name" validate:"required,min=1,max=100"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,max=100"`
+	Name      string `json:"name" validate:"required,min=1,max=100"`
+	Email     string `json:"email" validate:"required,email"`
+	Password  string `json:"password" validate:"required,min=8,max=100"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 func (i *RegisterUserInput) Validate() error {
@@ -30,6 +40,9 @@ func (i *RegisterUserInput) Validate() error {
 	if i.Email == "" {
 		return errors.New("email is required")
 	}
+	if !isValidEmail(i.Email) {
+		return errors.New("email is invalid")
+	}
 	if i.Password == "" {
 		return errors.New("password is required")
 	}
@@ -37,12 +50,192 @@ func (i *RegisterUserInput) Validate() error {
 }
 
 type CheckAuthenticationInput struct {
-	Email    string `json:"email" validate:"required,email"`
+	UserID uuid.UUID `json:"-"`
 }
 
 func (i *CheckAuthenticationInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	IPAddress    string `json:"-"`
+	UserAgent    string `json:"-"`
+}
+
+func (i *RefreshTokenInput) Validate() error {
+	if i.RefreshToken == "" {
+		return errors.New("refresh_token is required")
+	}
+	return nil
+}
+
+type AuthenticateInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (i *AuthenticateInput) Validate() error {
+	if i.Token == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
+
+// LogoutInput の ImpersonatorID はなりすまし中のトークンの場合に設定し、なりすましの終了として記録します
+type LogoutInput struct {
+	TokenID        uuid.UUID  `json:"token_id" validate:"required"`
+	SessionID      uuid.UUID  `json:"session_id" validate:"required"`
+	ExpiresAt      time.Time  `json:"expires_at" validate:"required"`
+	UserID         uuid.UUID  `json:"-"`
+	ImpersonatorID *uuid.UUID `json:"-"`
+	IPAddress      string     `json:"-"`
+	UserAgent      string     `json:"-"`
+}
+
+func (i *LogoutInput) Validate() error {
+	if i.TokenID == uuid.Nil {
+		return errors.New("token_id is required")
+	}
+	if i.SessionID == uuid.Nil {
+		return errors.New("session_id is required")
+	}
+	return nil
+}
+
+type LogoutAllInput struct {
+	UserID    uuid.UUID `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *LogoutAllInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (i *ForgotPasswordInput) Validate() error {
 	if i.Email == "" {
 		return errors.New("email is required")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+type ResetPasswordInput struct {
+	Token     string `json:"token" validate:"required"`
+	Password  string `json:"password" validate:"required,min=8,max=100"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (i *ResetPasswordInput) Validate() error {
+	if i.Token == "" {
+		return errors.New("token is required")
+	}
+	if i.Password == "" {
+		return errors.New("password is required")
+	}
+	return nil
+}
+
+type VerifyEmailInput struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (i *VerifyEmailInput) Validate() error {
+	if i.Token == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
+
+type ResendVerificationEmailInput struct {
+	UserID uuid.UUID `json:"-"`
+}
+
+func (i *ResendVerificationEmailInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+// isValidEmail は表示名などを含まない素のメールアドレスかどうかを判定します
+func isValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+type StartOIDCInput struct {
+	Provider string `json:"provider" validate:"required"`
+}
+
+func (i *StartOIDCInput) Validate() error {
+	if i.Provider == "" {
+		return errors.New("provider is required")
+	}
+	return nil
+}
+
+// OIDCCallbackInput の StateToken は StartOIDC でブラウザの Cookie に保存したトークンです
+type OIDCCallbackInput struct {
+	Provider   string `json:"provider" validate:"required"`
+	Code       string `json:"code" validate:"required"`
+	State      string `json:"state" validate:"required"`
+	StateToken string `json:"-"`
+	IPAddress  string `json:"-"`
+	UserAgent  string `json:"-"`
+}
+
+func (i *OIDCCallbackInput) Validate() error {
+	if i.Provider == "" {
+		return errors.New("provider is required")
+	}
+	if i.Code == "" {
+		return errors.New("code is required")
+	}
+	if i.State == "" {
+		return errors.New("state is required")
+	}
+	if i.StateToken == "" {
+		return errors.New("login state cookie is missing")
+	}
+	return nil
+}
+
+type RequestMagicLinkInput struct {
+	Email     string `json:"email" validate:"required,email"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (i *RequestMagicLinkInput) Validate() error {
+	if i.Email == "" {
+		return errors.New("email is required")
+	}
+	if !isValidEmail(i.Email) {
+		return errors.New("email is invalid")
+	}
+	return nil
+}
+
+type ConsumeMagicLinkInput struct {
+	Token     string `json:"token" validate:"required"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (i *ConsumeMagicLinkInput) Validate() error {
+	if i.Token == "" {
+		return errors.New("token is required")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package input
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+type EnrollMFAInput struct {
+	UserID    uuid.UUID `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *EnrollMFAInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+type ConfirmMFAInput struct {
+	UserID    uuid.UUID `json:"-"`
+	Code      string    `json:"code" validate:"required"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *ConfirmMFAInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	if i.Code == "" {
+		return errors.New("code is required")
+	}
+	return nil
+}
+
+// DisableMFAInput の Code には認証アプリのコードかリカバリーコードを指定します
+type DisableMFAInput struct {
+	UserID    uuid.UUID `json:"-"`
+	Code      string    `json:"code" validate:"required"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *DisableMFAInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	if i.Code == "" {
+		return errors.New("code is required")
+	}
+	return nil
+}
+
+// VerifyMFAInput の Code には認証アプリのコードかリカバリーコードを指定します
+type VerifyMFAInput struct {
+	MFAToken  string `json:"mfa_token" validate:"required"`
+	Code      string `json:"code" validate:"required"`
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+func (i *VerifyMFAInput) Validate() error {
+	if i.MFAToken == "" {
+		return errors.New("mfa_token is required")
+	}
+	if i.Code == "" {
+		return errors.New("code is required")
+	}
+	return nil
+}
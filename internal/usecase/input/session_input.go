@@ -0,0 +1,37 @@
+package input
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ListSessionsInput の CurrentSessionID はリクエストに使われたトークンのセッションです
+type ListSessionsInput struct {
+	UserID           uuid.UUID `json:"-"`
+	CurrentSessionID uuid.UUID `json:"-"`
+}
+
+func (i *ListSessionsInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+type RevokeSessionInput struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	IPAddress string    `json:"-"`
+	UserAgent string    `json:"-"`
+}
+
+func (i *RevokeSessionInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
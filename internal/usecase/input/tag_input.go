@@ -0,0 +1,90 @@
+package input
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxTodoTags は 1 つの todo に付けられるタグの数の上限です
+const MaxTodoTags = 20
+
+type ListTagsInput struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+func (i *ListTagsInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+type GetTagInput struct {
+	ID     uuid.UUID `json:"id" validate:"required"`
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+func (i *GetTagInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+type CreateTagInput struct {
+	UserID uuid.UUID `json:"-"`
+	Name   string    `json:"name" validate:"required,min=1,max=50"`
+}
+
+func (i *CreateTagInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return validateTagName(i.Name)
+}
+
+type UpdateTagInput struct {
+	ID     uuid.UUID `json:"-"`
+	UserID uuid.UUID `json:"-"`
+	Name   string    `json:"name" validate:"required,min=1,max=50"`
+}
+
+func (i *UpdateTagInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return validateTagName(i.Name)
+}
+
+type DeleteTagInput struct {
+	ID     uuid.UUID `json:"id" validate:"required"`
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+func (i *DeleteTagInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	return nil
+}
+
+// validateTagName は前後の空白を除いた名前が 1 文字以上 50 文字以下かどうかを検証します
+func validateTagName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 50 {
+		return errors.New("name must be between 1 and 50 characters")
+	}
+	return nil
+}
@@ -2,18 +2,96 @@ package input
 
 import (
 	"errors"
+	"go-boilerplate/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// TodoView は期限を基準にした一覧の表示です。いずれも未完了 (open と in_progress) の todo だけを対象にします
+type TodoView string
+
+const (
+	// TodoViewToday は期限が今日の todo です
+	TodoViewToday TodoView = "today"
+	// TodoViewOverdue は期限を過ぎた todo です
+	TodoViewOverdue TodoView = "overdue"
+	// TodoViewUpcoming は期限が明日から 7 日以内の todo です
+	TodoViewUpcoming TodoView = "upcoming"
+)
+
+func (v TodoView) IsValid() bool {
+	switch v {
+	case TodoViewToday, TodoViewOverdue, TodoViewUpcoming:
+		return true
+	}
+	return false
+}
+
+// TagMatch は複数のタグを指定したときの絞り込み方です
+type TagMatch string
+
+const (
+	// TagMatchAny はいずれかのタグが付いた todo です
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll はすべてのタグが付いた todo です
+	TagMatchAll TagMatch = "all"
+)
+
+func (m TagMatch) IsValid() bool {
+	return m == TagMatchAny || m == TagMatchAll
+}
+
+// ListTodoInput の View は Timezone (ユーザーのタイムゾーン) の日付で計算します。
+// Sort は "-updated_at,title" のようにカンマ区切りで項目を並べ、先頭の - で降順を表します
 type ListTodoInput struct {
-	UserID uuid.UUID `json:"user_id" validate:"required"`
+	UserID      uuid.UUID         `json:"user_id" validate:"required"`
+	Query       string            `json:"q" validate:"max=100"`
+	CreatedFrom *time.Time        `json:"created_from"`
+	CreatedTo   *time.Time        `json:"created_to"`
+	UpdatedFrom *time.Time        `json:"updated_from"`
+	UpdatedTo   *time.Time        `json:"updated_to"`
+	Status      domain.TodoStatus `json:"status"`
+	TagIDs      []uuid.UUID       `json:"tags"`
+	TagMatch    TagMatch          `json:"tag_match"`
+	View        TodoView          `json:"view"`
+	Timezone    string            `json:"-"`
+	Sort        string            `json:"sort"`
+	Cursor      string            `json:"cursor"`
+	Limit       int               `json:"limit" validate:"min=1,max=100"`
 }
 
 func (i *ListTodoInput) Validate() error {
 	if i.UserID == uuid.Nil {
 		return errors.New("user_id is required")
 	}
+	if len(i.Query) > 100 {
+		return errors.New("q must be less than 100 characters")
+	}
+	if i.CreatedFrom != nil && i.CreatedTo != nil && i.CreatedFrom.After(*i.CreatedTo) {
+		return errors.New("created_from must be before created_to")
+	}
+	if i.UpdatedFrom != nil && i.UpdatedTo != nil && i.UpdatedFrom.After(*i.UpdatedTo) {
+		return errors.New("updated_from must be before updated_to")
+	}
+	if i.Status != "" && !i.Status.IsValid() {
+		return errors.New("status is invalid")
+	}
+	if i.View != "" && !i.View.IsValid() {
+		return errors.New("view must be one of today, overdue or upcoming")
+	}
+	if i.View != "" && i.Status != "" {
+		return errors.New("status cannot be combined with view")
+	}
+	if len(i.TagIDs) > MaxTodoTags {
+		return errors.New("tags must be 20 or fewer")
+	}
+	if i.TagMatch != "" && !i.TagMatch.IsValid() {
+		return errors.New("tag_match must be any or all")
+	}
+	if i.Limit < 1 || i.Limit > MaxPerPage {
+		return errors.New("limit must be between 1 and 100")
+	}
 	return nil
 }
 
@@ -32,18 +110,25 @@ func (i *GetTodoInput) Validate() error {
 	return nil
 }
 
-
+// CreateTodoInput の DueAt は "2025-04-01T18:00:00+09:00" のようにオフセット付きで受け取ります
 type CreateTodoInput struct {
-	UserID uuid.UUID `json:"user_id" validate:"required"`
-	Title     string  `json:"title" validate:"required,min=1,max=100"`
-	Content   *string `json:"content" validate:"omitempty,max=1000"`
+	UserID   uuid.UUID           `json:"user_id" validate:"required"`
+	Title    string              `json:"title" validate:"required,min=1,max=100"`
+	Content  *string             `json:"content" validate:"omitempty,max=1000"`
+	DueAt    *time.Time          `json:"due_at"`
+	Priority domain.TodoPriority `json:"priority" validate:"min=0,max=3"`
+	TagIDs   []uuid.UUID         `json:"tag_ids"`
 }
 
+// UpdateTodoInput の TagIDs は省略するとタグを変更せず、空の配列ですべて外します
 type UpdateTodoInput struct {
-	ID        uuid.UUID `json:"id" validate:"required"`
-	UserID uuid.UUID `json:"user_id" validate:"required"`
-	Title     string    `json:"title" validate:"required,min=1,max=100"`
-	Content   *string   `json:"content" validate:"omitempty,max=1000"`
+	ID       uuid.UUID           `json:"id" validate:"required"`
+	UserID   uuid.UUID           `json:"user_id" validate:"required"`
+	Title    string              `json:"title" validate:"required,min=1,max=100"`
+	Content  *string             `json:"content" validate:"omitempty,max=1000"`
+	DueAt    *time.Time          `json:"due_at"`
+	Priority domain.TodoPriority `json:"priority" validate:"min=0,max=3"`
+	TagIDs   *[]uuid.UUID        `json:"tag_ids"`
 }
 
 type DeleteTodoInput struct {
@@ -64,7 +149,10 @@ func (i *CreateTodoInput) Validate() error {
 	if i.Content != nil && len(*i.Content) > 1000 {
 		return errors.New("content must be less than 1000 characters")
 	}
-	return nil
+	if len(i.TagIDs) > MaxTodoTags {
+		return errors.New("tag_ids must be 20 or fewer")
+	}
+	return validateTodoSchedule(i.DueAt, i.Priority)
 }
 
 func (i *UpdateTodoInput) Validate() error {
@@ -83,16 +171,49 @@ func (i *UpdateTodoInput) Validate() error {
 	if i.Content != nil && len(*i.Content) > 1000 {
 		return errors.New("content must be less than 1000 characters")
 	}
+	if i.TagIDs != nil && len(*i.TagIDs) > MaxTodoTags {
+		return errors.New("tag_ids must be 20 or fewer")
+	}
+	return validateTodoSchedule(i.DueAt, i.Priority)
+}
+
+func (i *DeleteTodoInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
+	}
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
 	return nil
 }
 
+// ChangeTodoStatusInput の Status は遷移先のステータスです
+type ChangeTodoStatusInput struct {
+	ID     uuid.UUID         `json:"id" validate:"required"`
+	UserID uuid.UUID         `json:"user_id" validate:"required"`
+	Status domain.TodoStatus `json:"status" validate:"required"`
+}
 
-func (i *DeleteTodoInput) Validate() error {
+func (i *ChangeTodoStatusInput) Validate() error {
 	if i.ID == uuid.Nil {
 		return errors.New("id is required")
 	}
 	if i.UserID == uuid.Nil {
 		return errors.New("user_id is required")
 	}
+	if !i.Status.IsValid() {
+		return errors.New("status is invalid")
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// validateTodoSchedule は作成と更新で共通の期限と優先度の検証です
+func validateTodoSchedule(dueAt *time.Time, priority domain.TodoPriority) error {
+	if dueAt != nil && (dueAt.Year() < 1970 || dueAt.Year() > 9998) {
+		return errors.New("due_at is out of range")
+	}
+	if !priority.IsValid() {
+		return errors.New("priority must be between 0 and 3")
+	}
+	return nil
+}
@@ -1,14 +1,95 @@
 package input
 
-import "errors"
+import (
+	"errors"
+	"time"
 
-type GetUserByEmailInput struct {
-	Email string `json:"email" validate:"required,email"`
+	"github.com/google/uuid"
+)
+
+type GetUserInput struct {
+	ID uuid.UUID `json:"id" validate:"required"`
 }
 
-func (i *GetUserByEmailInput) Validate() error {
-	if i.Email == "" {
-		return errors.New("email is required")
+func (i *GetUserInput) Validate() error {
+	if i.ID == uuid.Nil {
+		return errors.New("id is required")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// UpdateProfileInput の Timezone は "Asia/Tokyo" のような IANA タイムゾーン名です。
+// メールアドレスを変更する場合は CurrentPassword が必要です
+type UpdateProfileInput struct {
+	UserID          uuid.UUID `json:"-"`
+	Name            *string   `json:"name" validate:"omitempty,min=1,max=100"`
+	Email           *string   `json:"email" validate:"omitempty,email"`
+	Timezone        *string   `json:"timezone" validate:"omitempty,timezone"`
+	CurrentPassword string    `json:"current_password"`
+	IPAddress       string    `json:"-"`
+	UserAgent       string    `json:"-"`
+}
+
+func (i *UpdateProfileInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	if i.Name != nil && (*i.Name == "" || len(*i.Name) > 100) {
+		return errors.New("name must be between 1 and 100 characters")
+	}
+	if i.Email != nil && !isValidEmail(*i.Email) {
+		return errors.New("email is invalid")
+	}
+	if i.Timezone != nil && !isValidTimezone(*i.Timezone) {
+		return errors.New("timezone must be an IANA time zone name")
+	}
+	return nil
+}
+
+// isValidTimezone はサーバーの設定に依存する "Local" を除いた、読み込めるタイムゾーン名かどうかを判定します
+func isValidTimezone(name string) bool {
+	if name == "" || name == "Local" || len(name) > 64 {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+type ChangePasswordInput struct {
+	UserID          uuid.UUID `json:"-"`
+	SessionID       uuid.UUID `json:"-"`
+	CurrentPassword string    `json:"current_password" validate:"required"`
+	NewPassword     string    `json:"new_password" validate:"required,min=8,max=100"`
+	IPAddress       string    `json:"-"`
+	UserAgent       string    `json:"-"`
+}
+
+func (i *ChangePasswordInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	if i.CurrentPassword == "" {
+		return errors.New("current_password is required")
+	}
+	if i.NewPassword == "" {
+		return errors.New("new_password is required")
+	}
+	return nil
+}
+
+type DeleteAccountInput struct {
+	UserID          uuid.UUID `json:"-"`
+	CurrentPassword string    `json:"current_password" validate:"required"`
+	IPAddress       string    `json:"-"`
+	UserAgent       string    `json:"-"`
+}
+
+func (i *DeleteAccountInput) Validate() error {
+	if i.UserID == uuid.Nil {
+		return errors.New("user_id is required")
+	}
+	if i.CurrentPassword == "" {
+		return errors.New("current_password is required")
+	}
+	return nil
+}
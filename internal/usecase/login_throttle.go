@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"strings"
+	"time"
+)
+
+type LoginThrottleConfig struct {
+	// AccountThreshold 回連続で失敗したアカウントをロックします
+	AccountThreshold int
+	// IPThreshold 回連続で失敗した IP アドレスをロックします
+	IPThreshold int
+	// BaseLockDuration は最初のロック時間です。以降は失敗するたびに倍になります
+	BaseLockDuration time.Duration
+	// MaxLockDuration はロック時間の上限です
+	MaxLockDuration time.Duration
+	// 最後の失敗かロックの解除から FailureWindow が過ぎると失敗回数を数え直します。
+	// ロックが明けてすぐ失敗すれば、前回より長くロックされます
+	FailureWindow time.Duration
+}
+
+// loginThrottle はアカウントと IP アドレスごとにログイン失敗を数え、しきい値を超えたら指数的に長くロックします
+type loginThrottle struct {
+	throttleRepo     repository.LoginThrottleRepository
+	lockoutEventRepo repository.LockoutEventRepository
+	config           LoginThrottleConfig
+}
+
+type throttleKey struct {
+	scope      domain.LoginThrottleScope
+	identifier string
+	threshold  int
+}
+
+func (t *loginThrottle) keys(email string, ipAddress string) []throttleKey {
+	keys := []throttleKey{{
+		scope:      domain.LoginThrottleScopeAccount,
+		identifier: strings.ToLower(email),
+		threshold:  t.config.AccountThreshold,
+	}}
+	if ipAddress != "" {
+		keys = append(keys, throttleKey{
+			scope:      domain.LoginThrottleScopeIP,
+			identifier: ipAddress,
+			threshold:  t.config.IPThreshold,
+		})
+	}
+	return keys
+}
+
+// check はアカウントまたは IP アドレスがロック中であれば AccountLocked エラーを返します
+func (t *loginThrottle) check(ctx context.Context, email string, ipAddress string) error {
+	for _, key := range t.keys(email, ipAddress) {
+		throttle, err := t.throttleRepo.Find(ctx, &dto.FindLoginThrottleInput{
+			Scope:      key.scope,
+			Identifier: key.identifier,
+		})
+		if err != nil {
+			if apperrors.Is(err, apperrors.NotFound) {
+				continue
+			}
+			return err
+		}
+		if throttle.LockedUntil != nil && time.Now().Before(*throttle.LockedUntil) {
+			return apperrors.NewAccountLockedError("too many failed login attempts", time.Until(*throttle.LockedUntil))
+		}
+	}
+	return nil
+}
+
+func (t *loginThrottle) recordFailure(ctx context.Context, email string, ipAddress string) error {
+	for _, key := range t.keys(email, ipAddress) {
+		throttle, err := t.throttleRepo.RecordFailure(ctx, &dto.RecordLoginFailureInput{
+			Scope:      key.scope,
+			Identifier: key.identifier,
+			Window:     t.config.FailureWindow,
+		})
+		if err != nil {
+			return err
+		}
+		if throttle.FailedAttempts < key.threshold {
+			continue
+		}
+
+		lockedUntil := time.Now().Add(t.lockDuration(throttle.FailedAttempts - key.threshold))
+		if err := t.throttleRepo.Lock(ctx, &dto.LockLoginThrottleInput{
+			Scope:       key.scope,
+			Identifier:  key.identifier,
+			LockedUntil: lockedUntil,
+		}); err != nil {
+			return err
+		}
+		if _, err := t.lockoutEventRepo.Create(ctx, &dto.CreateLockoutEventInput{
+			Scope:          key.scope,
+			Identifier:     key.identifier,
+			FailedAttempts: throttle.FailedAttempts,
+			LockedUntil:    lockedUntil,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reset はログインに成功したアカウントの失敗回数を消します。IP アドレスの失敗回数は残します
+func (t *loginThrottle) reset(ctx context.Context, email string) error {
+	return t.throttleRepo.Reset(ctx, &dto.ResetLoginThrottleInput{
+		Scope:      domain.LoginThrottleScopeAccount,
+		Identifier: strings.ToLower(email),
+	})
+}
+
+func (t *loginThrottle) lockDuration(exceeded int) time.Duration {
+	duration := t.config.BaseLockDuration
+	for i := 0; i < exceeded && duration < t.config.MaxLockDuration; i++ {
+		duration *= 2
+	}
+	if duration > t.config.MaxLockDuration {
+		return t.config.MaxLockDuration
+	}
+	return duration
+}
@@ -0,0 +1,180 @@
+package usecase_test
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase/input"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoginLockoutEscalates(t *testing.T) {
+	env := newAuthTestEnv(t)
+	env.users.add("locked@example.com")
+	login := &input.LoginInput{Email: "locked@example.com", Password: "wrong-password"}
+
+	for i := 1; i < testThrottleConfig.AccountThreshold; i++ {
+		if _, err := env.authUseCase.Login(context.Background(), login); !apperrors.Is(err, apperrors.Unauthorized) {
+			t.Fatalf("expected failure %d to be rejected without a lock, got %v", i, err)
+		}
+	}
+	if len(env.lockouts.events) != 0 {
+		t.Fatalf("expected no lock before the threshold, got %d", len(env.lockouts.events))
+	}
+
+	// each failure right after a lock ends doubles the lock, even once it outlasts the failure window
+	expected := []time.Duration{
+		time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute,
+		16 * time.Minute, 32 * time.Minute, time.Hour, time.Hour,
+	}
+	for i, want := range expected {
+		if i > 0 {
+			env.throttles.elapse(expected[i-1])
+		}
+		if _, err := env.authUseCase.Login(context.Background(), login); !apperrors.Is(err, apperrors.Unauthorized) {
+			t.Fatalf("lock %d: expected the failed login to be rejected, got %v", i+1, err)
+		}
+		env.lockouts.expectLast(t, want)
+
+		_, err := env.authUseCase.Login(context.Background(), login)
+		if !apperrors.Is(err, apperrors.AccountLocked) {
+			t.Fatalf("lock %d: expected the account to be locked, got %v", i+1, err)
+		}
+	}
+}
+
+func TestLoginLockoutResetsAfterQuietPeriod(t *testing.T) {
+	env := newAuthTestEnv(t)
+	env.users.add("quiet@example.com")
+	login := &input.LoginInput{Email: "quiet@example.com", Password: "wrong-password"}
+
+	for i := 0; i < testThrottleConfig.AccountThreshold; i++ {
+		_, _ = env.authUseCase.Login(context.Background(), login)
+	}
+	env.lockouts.expectLast(t, testThrottleConfig.BaseLockDuration)
+
+	// no failures for a whole window after the lock ended starts the count over
+	env.throttles.elapse(testThrottleConfig.BaseLockDuration + testThrottleConfig.FailureWindow + time.Minute)
+	if _, err := env.authUseCase.Login(context.Background(), login); !apperrors.Is(err, apperrors.Unauthorized) {
+		t.Fatalf("expected the failed login to be rejected, got %v", err)
+	}
+	if len(env.lockouts.events) != 1 {
+		t.Fatalf("expected the count to start over without a new lock, got %d locks", len(env.lockouts.events))
+	}
+}
+
+// memoryLoginThrottleRepository は RecordFailure の UPSERT と同じく、最後の失敗かロックの解除の遅いほうから
+// Window が過ぎていれば数え直します。elapse で時間の経過を再現します
+type memoryLoginThrottleRepository struct {
+	mu        sync.Mutex
+	throttles map[string]*dto.LoginThrottleOutput
+}
+
+func throttleKey(scope domain.LoginThrottleScope, identifier string) string {
+	return string(scope) + ":" + identifier
+}
+
+// elapse は記録された時刻をすべて d だけ過去にずらし、d が経過した状態にします
+func (r *memoryLoginThrottleRepository) elapse(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, throttle := range r.throttles {
+		throttle.LastFailedAt = throttle.LastFailedAt.Add(-d)
+		if throttle.LockedUntil != nil {
+			lockedUntil := throttle.LockedUntil.Add(-d)
+			throttle.LockedUntil = &lockedUntil
+		}
+	}
+}
+
+func (r *memoryLoginThrottleRepository) Find(ctx context.Context, input *dto.FindLoginThrottleInput) (*dto.LoginThrottleOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	throttle, ok := r.throttles[throttleKey(input.Scope, input.Identifier)]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("login throttle not found", nil)
+	}
+	found := *throttle
+	return &found, nil
+}
+
+func (r *memoryLoginThrottleRepository) RecordFailure(ctx context.Context, input *dto.RecordLoginFailureInput) (*dto.LoginThrottleOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	key := throttleKey(input.Scope, input.Identifier)
+	throttle, ok := r.throttles[key]
+	if !ok {
+		throttle = &dto.LoginThrottleOutput{Scope: input.Scope, Identifier: input.Identifier}
+		r.throttles[key] = throttle
+	}
+	lastActivity := throttle.LastFailedAt
+	if throttle.LockedUntil != nil && throttle.LockedUntil.After(lastActivity) {
+		lastActivity = *throttle.LockedUntil
+	}
+	if lastActivity.Before(now.Add(-input.Window)) {
+		throttle.FailedAttempts = 1
+	} else {
+		throttle.FailedAttempts++
+	}
+	throttle.LastFailedAt = now
+	recorded := *throttle
+	return &recorded, nil
+}
+
+func (r *memoryLoginThrottleRepository) Lock(ctx context.Context, input *dto.LockLoginThrottleInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if throttle, ok := r.throttles[throttleKey(input.Scope, input.Identifier)]; ok {
+		lockedUntil := input.LockedUntil
+		throttle.LockedUntil = &lockedUntil
+	}
+	return nil
+}
+
+func (r *memoryLoginThrottleRepository) Reset(ctx context.Context, input *dto.ResetLoginThrottleInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.throttles, throttleKey(input.Scope, input.Identifier))
+	return nil
+}
+
+type memoryLockoutEventRepository struct {
+	repository.LockoutEventRepository
+	mu     sync.Mutex
+	events []dto.CreateLockoutEventInput
+	// createdAt は各ロックを記録した時刻で、ロック時間の計算に使います
+	createdAt []time.Time
+}
+
+// expectLast は最後のロックが want の長さだったことを確認します
+func (r *memoryLockoutEventRepository) expectLast(t *testing.T, want time.Duration) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.events) == 0 {
+		t.Fatalf("expected a %s lock, got none", want)
+	}
+	last := len(r.events) - 1
+	got := r.events[last].LockedUntil.Sub(r.createdAt[last])
+	if got < want-time.Second || got > want+time.Second {
+		t.Fatalf("expected a %s lock, got %s", want, got)
+	}
+}
+
+func (r *memoryLockoutEventRepository) Create(ctx context.Context, input *dto.CreateLockoutEventInput) (*dto.LockoutEventOutput, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, *input)
+	r.createdAt = append(r.createdAt, time.Now())
+	return &dto.LockoutEventOutput{
+		Scope:          input.Scope,
+		Identifier:     input.Identifier,
+		FailedAttempts: input.FailedAttempts,
+		LockedUntil:    input.LockedUntil,
+	}, nil
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/auth"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/pkg/totp"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase/input"
+	"go-boilerplate/internal/usecase/output"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const recoveryCodeCount = 10
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type MFAUseCase interface {
+	Enroll(ctx context.Context, input *input.EnrollMFAInput) (*output.MFAEnrollmentOutput, error)
+	Confirm(ctx context.Context, input *input.ConfirmMFAInput) (*output.MFARecoveryCodesOutput, error)
+	Disable(ctx context.Context, input *input.DisableMFAInput) error
+}
+
+type mfaUseCase struct {
+	userRepo    repository.UserRepository
+	mfaRepo     repository.MFARepository
+	auditLogger AuditLogger
+	issuer      string
+}
+
+func NewMFAUseCase(userRepo repository.UserRepository, mfaRepo repository.MFARepository, auditLogger AuditLogger, issuer string) MFAUseCase {
+	return &mfaUseCase{userRepo: userRepo, mfaRepo: mfaRepo, auditLogger: auditLogger, issuer: issuer}
+}
+
+func (u *mfaUseCase) Enroll(ctx context.Context, input *input.EnrollMFAInput) (*output.MFAEnrollmentOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.UserID})
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := mfaEnabled(ctx, u.mfaRepo, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, apperrors.NewAlreadyExistsError("two-factor authentication is already enabled", nil)
+	}
+
+	// starting over replaces any unconfirmed secret
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to create mfa secret", err)
+	}
+	encrypted, err := auth.EncryptSecret(secret)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to encrypt mfa secret", err)
+	}
+	if err := u.mfaRepo.Save(ctx, &dto.SaveMFACredentialInput{UserID: user.ID, Secret: encrypted}); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionMFAEnroll,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+
+	return &output.MFAEnrollmentOutput{
+		Secret: secret,
+		URI:    totp.URI(u.issuer, user.Email, secret),
+	}, nil
+}
+
+func (u *mfaUseCase) Confirm(ctx context.Context, input *input.ConfirmMFAInput) (*output.MFARecoveryCodesOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.UserID})
+	if err != nil {
+		return nil, err
+	}
+	credential, err := u.mfaRepo.FindByUserID(ctx, &dto.FindMFACredentialInput{UserID: user.ID})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return nil, apperrors.NewValidationError("two-factor authentication enrollment has not been started", nil)
+		}
+		return nil, err
+	}
+	if credential.ConfirmedAt != nil {
+		return nil, apperrors.NewAlreadyExistsError("two-factor authentication is already enabled", nil)
+	}
+
+	secret, err := auth.DecryptSecret(credential.Secret)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to decrypt mfa secret", err)
+	}
+	step, ok := totp.Validate(secret, input.Code, time.Now())
+	if !ok {
+		recordAudit(ctx, u.auditLogger, &AuditEntry{
+			Action:    domain.AuditActionMFAEnable,
+			Outcome:   domain.AuditOutcomeFailure,
+			ActorID:   &user.ID,
+			TargetID:  &user.ID,
+			IPAddress: input.IPAddress,
+			UserAgent: input.UserAgent,
+			Metadata:  map[string]string{"reason": "invalid_code"},
+		})
+		return nil, apperrors.NewValidationError("invalid two-factor code", nil)
+	}
+	if err := u.mfaRepo.Confirm(ctx, &dto.ConfirmMFACredentialInput{UserID: user.ID, Step: step}); err != nil {
+		return nil, err
+	}
+
+	codes, err := issueRecoveryCodes(ctx, u.mfaRepo, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionMFAEnable,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+	return &output.MFARecoveryCodesOutput{RecoveryCodes: codes}, nil
+}
+
+func (u *mfaUseCase) Disable(ctx context.Context, input *input.DisableMFAInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{ID: input.UserID})
+	if err != nil {
+		return err
+	}
+	enabled, err := mfaEnabled(ctx, u.mfaRepo, user.ID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return apperrors.NewValidationError("two-factor authentication is not enabled", nil)
+	}
+
+	// require a fresh second factor so a stolen access token alone cannot turn 2FA off
+	if err := verifyMFACode(ctx, u.mfaRepo, user.ID, input.Code); err != nil {
+		if apperrors.Is(err, apperrors.Unauthorized) {
+			recordAudit(ctx, u.auditLogger, &AuditEntry{
+				Action:    domain.AuditActionMFADisable,
+				Outcome:   domain.AuditOutcomeFailure,
+				ActorID:   &user.ID,
+				TargetID:  &user.ID,
+				IPAddress: input.IPAddress,
+				UserAgent: input.UserAgent,
+				Metadata:  map[string]string{"reason": "invalid_code"},
+			})
+			return apperrors.NewValidationError("invalid two-factor code", nil)
+		}
+		return err
+	}
+
+	if err := u.mfaRepo.Delete(ctx, &dto.DeleteMFACredentialInput{UserID: user.ID}); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionMFADisable,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+	return nil
+}
+
+// mfaEnabled はユーザーが二要素認証の登録を完了しているかどうかを返します
+func mfaEnabled(ctx context.Context, mfaRepo repository.MFARepository, userID uuid.UUID) (bool, error) {
+	credential, err := mfaRepo.FindByUserID(ctx, &dto.FindMFACredentialInput{UserID: userID})
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return credential.ConfirmedAt != nil, nil
+}
+
+// verifyMFACode は認証アプリのコードかリカバリーコードを検証し、使用済みとして記録します。
+// コードが誤っているか再利用された場合は Unauthorized を返します
+func verifyMFACode(ctx context.Context, mfaRepo repository.MFARepository, userID uuid.UUID, code string) error {
+	invalid := apperrors.NewUnauthorizedError("invalid two-factor code", nil)
+
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		err := mfaRepo.ConsumeRecoveryCode(ctx, &dto.ConsumeMFARecoveryCodeInput{
+			UserID:   userID,
+			CodeHash: auth.HashToken(normalizeRecoveryCode(code)),
+		})
+		if apperrors.Is(err, apperrors.NotFound) {
+			return invalid
+		}
+		return err
+	}
+
+	credential, err := mfaRepo.FindByUserID(ctx, &dto.FindMFACredentialInput{UserID: userID})
+	if err != nil {
+		return err
+	}
+	secret, err := auth.DecryptSecret(credential.Secret)
+	if err != nil {
+		return apperrors.NewInternalError("failed to decrypt mfa secret", err)
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return invalid
+	}
+	// each time step is accepted only once
+	if err := mfaRepo.UseStep(ctx, &dto.UseMFAStepInput{UserID: userID, Step: step}); err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return invalid
+		}
+		return err
+	}
+	return nil
+}
+
+// issueRecoveryCodes は既存のリカバリーコードを破棄し、新しいコードを発行します
+func issueRecoveryCodes(ctx context.Context, mfaRepo repository.MFARepository, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, apperrors.NewInternalError("failed to create recovery code", err)
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes[i] = raw[:8] + "-" + raw[8:]
+		hashes[i] = auth.HashToken(raw)
+	}
+
+	if err := mfaRepo.ReplaceRecoveryCodes(ctx, &dto.ReplaceMFARecoveryCodesInput{
+		UserID:     userID,
+		CodeHashes: hashes,
+	}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
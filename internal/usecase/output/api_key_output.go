@@ -0,0 +1,49 @@
+package output
+
+import (
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyOutput struct {
+	ID         uuid.UUID           `json:"id"`
+	Name       string              `json:"name"`
+	Prefix     string              `json:"prefix"`
+	Scopes     []domain.Permission `json:"scopes"`
+	ExpiresAt  *time.Time          `json:"expires_at"`
+	LastUsedAt *time.Time          `json:"last_used_at"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// CreatedAPIKeyOutput は作成直後のキーです。Key の平文を返すのはこの一度だけです
+type CreatedAPIKeyOutput struct {
+	APIKeyOutput
+	Key string `json:"key"`
+}
+
+type APIKeyListOutput struct {
+	Keys []APIKeyOutput `json:"keys"`
+}
+
+func NewAPIKeyOutput(key *dto.APIKeyOutput) *APIKeyOutput {
+	return &APIKeyOutput{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+func NewAPIKeyListOutput(keys []dto.APIKeyOutput) *APIKeyListOutput {
+	outputs := make([]APIKeyOutput, len(keys))
+	for i, key := range keys {
+		outputs[i] = *NewAPIKeyOutput(&key)
+	}
+	return &APIKeyListOutput{Keys: outputs}
+}
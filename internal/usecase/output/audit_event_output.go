@@ -0,0 +1,55 @@
+package output
+
+import (
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditEventOutput struct {
+	ID        uuid.UUID           `json:"id"`
+	Action    domain.AuditAction  `json:"action"`
+	Outcome   domain.AuditOutcome `json:"outcome"`
+	ActorID   *uuid.UUID          `json:"actor_id"`
+	TargetID  *uuid.UUID          `json:"target_id"`
+	IPAddress string              `json:"ip_address"`
+	UserAgent string              `json:"user_agent"`
+	Metadata  map[string]string   `json:"metadata"`
+	CreatedAt time.Time           `json:"created_at"`
+}
+
+type AuditEventListOutput struct {
+	Events  []AuditEventOutput `json:"events"`
+	Total   int64              `json:"total"`
+	Page    int                `json:"page"`
+	PerPage int                `json:"per_page"`
+}
+
+func NewAuditEventOutput(event *dto.AuditEventOutput) *AuditEventOutput {
+	return &AuditEventOutput{
+		ID:        event.ID,
+		Action:    event.Action,
+		Outcome:   event.Outcome,
+		ActorID:   event.ActorID,
+		TargetID:  event.TargetID,
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Metadata:  event.Metadata,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+func NewAuditEventListOutput(events *dto.AuditEventListOutput, page int, perPage int) *AuditEventListOutput {
+	outputs := make([]AuditEventOutput, len(events.Events))
+	for i, event := range events.Events {
+		outputs[i] = *NewAuditEventOutput(&event)
+	}
+	return &AuditEventListOutput{
+		Events:  outputs,
+		Total:   events.Total,
+		Page:    page,
+		PerPage: perPage,
+	}
+}
@@ -1,6 +1,69 @@
 package output
 
+import (
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/pkg/auth"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthOutput は認証結果です。二要素認証が必要な場合は MFAToken だけが設定され、
+// /auth/mfa/verify でコードと交換するまでアクセストークンは発行されません
 type AuthOutput struct {
-	Token string `json:"token"`
-	User  UserOutput `json:"user"`
-}
\ No newline at end of file
+	Token        string      `json:"token,omitempty"`
+	RefreshToken string      `json:"refresh_token,omitempty"`
+	ExpiresIn    int64       `json:"expires_in"`
+	User         *UserOutput `json:"user,omitempty"`
+	MFARequired  bool        `json:"mfa_required"`
+	MFAToken     string      `json:"mfa_token,omitempty"`
+}
+
+// AuthenticatedOutput は検証済みのアクセストークンと、その sub クレームから読み込んだユーザーです。
+// API キーで認証した場合は APIKeyID と Scopes が設定され、TokenID と SessionID は空になります。
+// 管理者がなりすましている場合は ImpersonatorID にその管理者が設定されます
+type AuthenticatedOutput struct {
+	User           UserOutput          `json:"user"`
+	TokenID        uuid.UUID           `json:"token_id"`
+	SessionID      uuid.UUID           `json:"session_id"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+	APIKeyID       *uuid.UUID          `json:"api_key_id,omitempty"`
+	Scopes         []domain.Permission `json:"scopes,omitempty"`
+	ImpersonatorID *uuid.UUID          `json:"impersonator_id,omitempty"`
+}
+
+// HasPermission はロールの権限に加え、API キーの場合はスコープにも含まれるかを判定します
+func (a *AuthenticatedOutput) HasPermission(permission domain.Permission) bool {
+	if !a.User.Role.HasPermission(permission) {
+		return false
+	}
+	if a.APIKeyID == nil {
+		return true
+	}
+	for _, scope := range a.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ImpersonationOutput はなりすまし用のアクセストークンです。リフレッシュトークンは発行しません
+type ImpersonationOutput struct {
+	Token          string     `json:"token"`
+	ExpiresIn      int64      `json:"expires_in"`
+	User           UserOutput `json:"user"`
+	ImpersonatorID uuid.UUID  `json:"impersonator_id"`
+}
+
+// JWKSOutput は他のサービスがアクセストークンを検証するための公開鍵セットです
+type JWKSOutput struct {
+	Keys []auth.JWK `json:"keys"`
+}
+
+// OIDCStartOutput の StateToken はブラウザの Cookie に保存し、コールバックで照合します
+type OIDCStartOutput struct {
+	AuthorizationURL string `json:"authorization_url"`
+	StateToken       string `json:"-"`
+	ExpiresIn        int64  `json:"-"`
+}
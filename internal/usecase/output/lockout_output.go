@@ -0,0 +1,51 @@
+package output
+
+import (
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LockoutOutput struct {
+	ID             uuid.UUID                 `json:"id"`
+	Scope          domain.LoginThrottleScope `json:"scope"`
+	Identifier     string                    `json:"identifier"`
+	FailedAttempts int                       `json:"failed_attempts"`
+	LockedUntil    time.Time                 `json:"locked_until"`
+	ClearedAt      *time.Time                `json:"cleared_at"`
+	CreatedAt      time.Time                 `json:"created_at"`
+}
+
+type LockoutListOutput struct {
+	Lockouts []LockoutOutput `json:"lockouts"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PerPage  int             `json:"per_page"`
+}
+
+func NewLockoutOutput(event *dto.LockoutEventOutput) *LockoutOutput {
+	return &LockoutOutput{
+		ID:             event.ID,
+		Scope:          event.Scope,
+		Identifier:     event.Identifier,
+		FailedAttempts: event.FailedAttempts,
+		LockedUntil:    event.LockedUntil,
+		ClearedAt:      event.ClearedAt,
+		CreatedAt:      event.CreatedAt,
+	}
+}
+
+func NewLockoutListOutput(events *dto.LockoutEventListOutput, page int, perPage int) *LockoutListOutput {
+	outputs := make([]LockoutOutput, len(events.Events))
+	for i, event := range events.Events {
+		outputs[i] = *NewLockoutOutput(&event)
+	}
+	return &LockoutListOutput{
+		Lockouts: outputs,
+		Total:    events.Total,
+		Page:     page,
+		PerPage:  perPage,
+	}
+}
@@ -0,0 +1,12 @@
+package output
+
+// MFAEnrollmentOutput は認証アプリに登録するための情報です
+type MFAEnrollmentOutput struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFARecoveryCodesOutput はリカバリーコードです。平文を返すのは発行時の一度だけです
+type MFARecoveryCodesOutput struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
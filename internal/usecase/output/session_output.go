@@ -0,0 +1,43 @@
+package output
+
+import (
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionOutput struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+	// Impersonated はサポート担当の管理者がなりすましで利用しているセッションです
+	Impersonated bool `json:"impersonated"`
+}
+
+type SessionListOutput struct {
+	Sessions []SessionOutput `json:"sessions"`
+}
+
+func NewSessionOutput(session *dto.SessionOutput, currentSessionID uuid.UUID) *SessionOutput {
+	return &SessionOutput{
+		ID:           session.ID,
+		UserAgent:    session.UserAgent,
+		IPAddress:    session.IPAddress,
+		CreatedAt:    session.CreatedAt,
+		LastSeenAt:   session.LastSeenAt,
+		Current:      session.ID == currentSessionID,
+		Impersonated: session.ImpersonatorID != nil,
+	}
+}
+
+func NewSessionListOutput(sessions []dto.SessionOutput, currentSessionID uuid.UUID) *SessionListOutput {
+	outputs := make([]SessionOutput, len(sessions))
+	for i, session := range sessions {
+		outputs[i] = *NewSessionOutput(&session, currentSessionID)
+	}
+	return &SessionListOutput{Sessions: outputs}
+}
@@ -0,0 +1,36 @@
+package output
+
+import (
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TagOutput struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TagListOutput struct {
+	Tags []TagOutput `json:"tags"`
+}
+
+func NewTagOutput(tag *dto.TagOutput) *TagOutput {
+	return &TagOutput{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt,
+		UpdatedAt: tag.UpdatedAt,
+	}
+}
+
+func NewTagListOutput(tags []dto.TagOutput) *TagListOutput {
+	outputs := make([]TagOutput, len(tags))
+	for i, tag := range tags {
+		outputs[i] = *NewTagOutput(&tag)
+	}
+	return &TagListOutput{Tags: outputs}
+}
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/infrastructure/persistence/dto"
 	"time"
 
@@ -8,25 +9,41 @@ import (
 )
 
 type TodoOutput struct {
-	ID        uuid.UUID `json:"id"`
-	Title     string    `json:"title"`
-	Content   *string   `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uuid.UUID           `json:"id"`
+	Title       string              `json:"title"`
+	Content     *string             `json:"content"`
+	Status      domain.TodoStatus   `json:"status"`
+	CompletedAt *time.Time          `json:"completed_at"`
+	DueAt       *time.Time          `json:"due_at"`
+	Priority    domain.TodoPriority `json:"priority"`
+	Tags        []TagOutput         `json:"tags"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
 }
 
 type TodoListOutput struct {
-	Todos []TodoOutput `json:"todos"`
-	Total int64        `json:"total"`
+	Todos      []TodoOutput `json:"todos"`
+	Total      int64        `json:"total"`
+	NextCursor *string      `json:"next_cursor"`
+	PrevCursor *string      `json:"prev_cursor"`
 }
 
 func NewTodoOutput(todo *dto.TodoOutput) *TodoOutput {
+	tags := make([]TagOutput, len(todo.Tags))
+	for i, tag := range todo.Tags {
+		tags[i] = *NewTagOutput(&tag)
+	}
 	return &TodoOutput{
-		ID:        todo.ID,
-		Title:     todo.Title,
-		Content:   todo.Content,
-		CreatedAt: todo.CreatedAt,
-		UpdatedAt: todo.UpdatedAt,
+		ID:          todo.ID,
+		Title:       todo.Title,
+		Content:     todo.Content,
+		Status:      todo.Status,
+		CompletedAt: todo.CompletedAt,
+		DueAt:       todo.DueAt,
+		Priority:    todo.Priority,
+		Tags:        tags,
+		CreatedAt:   todo.CreatedAt,
+		UpdatedAt:   todo.UpdatedAt,
 	}
 }
 
@@ -36,7 +53,9 @@ func NewTodoListOutput(todos *dto.TodoListOutput) *TodoListOutput {
 		outputs[i] = *NewTodoOutput(&todo)
 	}
 	return &TodoListOutput{
-		Todos: outputs,
-		Total: todos.Total,
+		Todos:      outputs,
+		Total:      todos.Total,
+		NextCursor: todos.NextCursor,
+		PrevCursor: todos.PrevCursor,
 	}
-}
\ No newline at end of file
+}
@@ -1,26 +1,55 @@
 package output
 
 import (
+	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/infrastructure/persistence/dto"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type UserListOutput struct {
+	Users   []UserOutput `json:"users"`
+	Total   int64        `json:"total"`
+	Page    int          `json:"page"`
+	PerPage int          `json:"per_page"`
+}
+
 type UserOutput struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uuid.UUID   `json:"id"`
+	Name            string      `json:"name"`
+	Email           string      `json:"email"`
+	Role            domain.Role `json:"role"`
+	Timezone        string      `json:"timezone"`
+	EmailVerifiedAt *time.Time  `json:"email_verified_at"`
+	DisabledAt      *time.Time  `json:"disabled_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
 }
 
 func ConvertUserOutput(user *dto.UserOutput) *UserOutput {
 	return &UserOutput{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Role:            user.Role,
+		Timezone:        user.Timezone,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		DisabledAt:      user.DisabledAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
-}
\ No newline at end of file
+}
+
+func NewUserListOutput(users *dto.UserListOutput, page int, perPage int) *UserListOutput {
+	outputs := make([]UserOutput, len(users.Users))
+	for i, user := range users.Users {
+		outputs[i] = *ConvertUserOutput(&user)
+	}
+	return &UserListOutput{
+		Users:   outputs,
+		Total:   users.Total,
+		Page:    page,
+		PerPage: perPage,
+	}
+}
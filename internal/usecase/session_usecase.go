@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase/input"
+	"go-boilerplate/internal/usecase/output"
+	"time"
+)
+
+// last_seen_at の更新間隔です。これより短い間隔のリクエストでは書き込みを省略します
+const sessionTouchInterval = time.Minute
+
+type SessionUseCase interface {
+	ListSessions(ctx context.Context, input *input.ListSessionsInput) (*output.SessionListOutput, error)
+	RevokeSession(ctx context.Context, input *input.RevokeSessionInput) error
+}
+
+type sessionUseCase struct {
+	sessionRepo      repository.SessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	auditLogger      AuditLogger
+}
+
+func NewSessionUseCase(
+	sessionRepo repository.SessionRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	auditLogger AuditLogger,
+) SessionUseCase {
+	return &sessionUseCase{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		auditLogger:      auditLogger,
+	}
+}
+
+func (u *sessionUseCase) ListSessions(ctx context.Context, input *input.ListSessionsInput) (*output.SessionListOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	sessions, err := u.sessionRepo.ListActive(ctx, &dto.ListActiveSessionsInput{UserID: input.UserID})
+	if err != nil {
+		return nil, err
+	}
+	return output.NewSessionListOutput(sessions, input.CurrentSessionID), nil
+}
+
+func (u *sessionUseCase) RevokeSession(ctx context.Context, input *input.RevokeSessionInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	// only the owner may sign a session out
+	if _, err := u.sessionRepo.FindActive(ctx, &dto.FindSessionInput{ID: input.ID, UserID: input.UserID}); err != nil {
+		return err
+	}
+	if err := revokeSession(ctx, u.refreshTokenRepo, u.revokedTokenRepo, input.ID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionSessionRevoke,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &input.UserID,
+		TargetID:  &input.UserID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+		Metadata:  map[string]string{"session_id": input.ID.String()},
+	})
+	return nil
+}
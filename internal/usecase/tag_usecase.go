@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase/input"
+	"go-boilerplate/internal/usecase/output"
+	"strings"
+)
+
+type TagUseCase interface {
+	ListTags(ctx context.Context, input *input.ListTagsInput) (*output.TagListOutput, error)
+	GetTag(ctx context.Context, input *input.GetTagInput) (*output.TagOutput, error)
+	CreateTag(ctx context.Context, input *input.CreateTagInput) (*output.TagOutput, error)
+	UpdateTag(ctx context.Context, input *input.UpdateTagInput) (*output.TagOutput, error)
+	DeleteTag(ctx context.Context, input *input.DeleteTagInput) error
+}
+
+type tagUseCase struct {
+	tagRepo repository.TagRepository
+}
+
+func NewTagUseCase(tagRepo repository.TagRepository) TagUseCase {
+	return &tagUseCase{tagRepo: tagRepo}
+}
+
+func (u *tagUseCase) ListTags(ctx context.Context, input *input.ListTagsInput) (*output.TagListOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	tags, err := u.tagRepo.List(ctx, &dto.ListTagsInput{UserID: input.UserID})
+	if err != nil {
+		return nil, err
+	}
+	return output.NewTagListOutput(tags), nil
+}
+
+func (u *tagUseCase) GetTag(ctx context.Context, input *input.GetTagInput) (*output.TagOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	tag, err := u.tagRepo.FindByID(ctx, &dto.FindTagInput{ID: input.ID, UserID: input.UserID})
+	if err != nil {
+		return nil, err
+	}
+	return output.NewTagOutput(tag), nil
+}
+
+func (u *tagUseCase) CreateTag(ctx context.Context, input *input.CreateTagInput) (*output.TagOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	tag, err := u.tagRepo.Create(ctx, &dto.CreateTagInput{
+		UserID: input.UserID,
+		Name:   strings.TrimSpace(input.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.NewTagOutput(tag), nil
+}
+
+func (u *tagUseCase) UpdateTag(ctx context.Context, input *input.UpdateTagInput) (*output.TagOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	tag, err := u.tagRepo.Update(ctx, &dto.UpdateTagInput{
+		ID:     input.ID,
+		UserID: input.UserID,
+		Name:   strings.TrimSpace(input.Name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.NewTagOutput(tag), nil
+}
+
+func (u *tagUseCase) DeleteTag(ctx context.Context, input *input.DeleteTagInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+
+	return u.tagRepo.Delete(ctx, &dto.DeleteTagInput{ID: input.ID, UserID: input.UserID})
+}
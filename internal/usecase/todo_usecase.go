@@ -2,11 +2,16 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/infrastructure/persistence/dto"
 	apperrors "go-boilerplate/internal/pkg/errors"
 	"go-boilerplate/internal/repository"
 	"go-boilerplate/internal/usecase/input"
 	"go-boilerplate/internal/usecase/output"
+	"slices"
+	"strings"
+	"time"
 )
 
 type TodoUseCase interface {
@@ -14,9 +19,19 @@ type TodoUseCase interface {
 	GetTodo(ctx context.Context, input *input.GetTodoInput) (*output.TodoOutput, error)
 	CreateTodo(ctx context.Context, input *input.CreateTodoInput) (*output.TodoOutput, error)
 	UpdateTodo(ctx context.Context, input *input.UpdateTodoInput) (*output.TodoOutput, error)
+	ChangeTodoStatus(ctx context.Context, input *input.ChangeTodoStatusInput) (*output.TodoOutput, error)
 	DeleteTodo(ctx context.Context, input *input.DeleteTodoInput) error
 }
 
+// todoTransitions は各ステータスから遷移できるステータスです。
+// 完了と取り消しは open に戻してからでないと別の状態にできません
+var todoTransitions = map[domain.TodoStatus][]domain.TodoStatus{
+	domain.TodoStatusOpen:       {domain.TodoStatusInProgress, domain.TodoStatusDone, domain.TodoStatusCancelled},
+	domain.TodoStatusInProgress: {domain.TodoStatusOpen, domain.TodoStatusDone, domain.TodoStatusCancelled},
+	domain.TodoStatusDone:       {domain.TodoStatusOpen},
+	domain.TodoStatusCancelled:  {domain.TodoStatusOpen},
+}
+
 type todoUseCase struct {
 	todoRepo repository.TodoRepository
 }
@@ -26,9 +41,36 @@ func NewTodoUseCase(todoRepo repository.TodoRepository) TodoUseCase {
 }
 
 func (u *todoUseCase) ListTodo(ctx context.Context, input *input.ListTodoInput) (*output.TodoListOutput, error) {
-	todos, err := u.todoRepo.FindAll(ctx, &dto.FindAllInput{
-		UserID: input.UserID,
-	})
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+	sort, err := parseTodoSort(input.Sort)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid sort", err)
+	}
+	findDTO := &dto.FindAllInput{
+		UserID:       input.UserID,
+		Query:        input.Query,
+		CreatedFrom:  input.CreatedFrom,
+		CreatedTo:    input.CreatedTo,
+		UpdatedFrom:  input.UpdatedFrom,
+		UpdatedTo:    input.UpdatedTo,
+		TagIDs:       input.TagIDs,
+		MatchAllTags: input.TagMatch == "all",
+		Sort:         sort,
+		Cursor:       input.Cursor,
+		Limit:        input.Limit,
+	}
+	if input.Status != "" {
+		findDTO.Statuses = []domain.TodoStatus{input.Status}
+	}
+	if input.View != "" {
+		if err := applyTodoView(findDTO, input.View, input.Timezone, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	todos, err := u.todoRepo.FindAll(ctx, findDTO)
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +78,58 @@ func (u *todoUseCase) ListTodo(ctx context.Context, input *input.ListTodoInput)
 	return output.NewTodoListOutput(todos), nil
 }
 
+// applyTodoView は期限の範囲と未完了のステータスで絞り込む条件を設定します。
+// 日付の境界はサーバーではなくユーザーのタイムゾーンで計算します
+func applyTodoView(findDTO *dto.FindAllInput, view input.TodoView, timezone string, now time.Time) error {
+	location := time.UTC
+	if timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			return apperrors.NewInternalError("failed to load user timezone", err)
+		}
+		location = loaded
+	}
+	now = now.In(location)
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	// AddDate keeps midnight across daylight saving changes, unlike adding 24 hours
+	startOfTomorrow := startOfToday.AddDate(0, 0, 1)
+
+	switch view {
+	case input.TodoViewToday:
+		findDTO.DueFrom, findDTO.DueTo = &startOfToday, &startOfTomorrow
+	case input.TodoViewOverdue:
+		findDTO.DueTo = &now
+	case input.TodoViewUpcoming:
+		endOfUpcoming := startOfTomorrow.AddDate(0, 0, 7)
+		findDTO.DueFrom, findDTO.DueTo = &startOfTomorrow, &endOfUpcoming
+	}
+	findDTO.Statuses = []domain.TodoStatus{domain.TodoStatusOpen, domain.TodoStatusInProgress}
+	return nil
+}
+
+// parseTodoSort は "-updated_at,title" 形式の並び順を解析します。許可されていない項目と重複は拒否します
+func parseTodoSort(value string) ([]dto.TodoSort, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var sort []dto.TodoSort
+	seen := map[dto.TodoSortField]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		field := dto.TodoSortField(strings.TrimPrefix(part, "-"))
+		if !field.IsValid() {
+			return nil, fmt.Errorf("cannot sort by %q", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("%s is specified more than once", field)
+		}
+		seen[field] = true
+		sort = append(sort, dto.TodoSort{Field: field, Desc: desc})
+	}
+	return sort, nil
+}
+
 func (u *todoUseCase) GetTodo(ctx context.Context, input *input.GetTodoInput) (*output.TodoOutput, error) {
 	if err := input.Validate(); err != nil {
 		return nil, apperrors.NewValidationError("invalid input parameters", err)
@@ -57,9 +151,12 @@ func (u *todoUseCase) CreateTodo(ctx context.Context, input *input.CreateTodoInp
 		return nil, apperrors.NewValidationError("invalid input parameters", err)
 	}
 	inputDTO := &dto.CreateTodoInput{
-		UserID:  input.UserID,
-		Title:   input.Title,
-		Content: input.Content,
+		UserID:   input.UserID,
+		Title:    input.Title,
+		Content:  input.Content,
+		DueAt:    input.DueAt,
+		Priority: input.Priority,
+		TagIDs:   input.TagIDs,
 	}
 	todo, err := u.todoRepo.Create(ctx, inputDTO)
 	if err != nil {
@@ -86,10 +183,13 @@ func (u *todoUseCase) UpdateTodo(ctx context.Context, input *input.UpdateTodoInp
 	}
 
 	inputUpdateDTO := &dto.UpdateTodoInput{
-		ID:      input.ID,
-		UserID:  input.UserID,
-		Title:   input.Title,
-		Content: input.Content,
+		ID:       input.ID,
+		UserID:   input.UserID,
+		Title:    input.Title,
+		Content:  input.Content,
+		DueAt:    input.DueAt,
+		Priority: input.Priority,
+		TagIDs:   input.TagIDs,
 	}
 
 	updated, err := u.todoRepo.Update(ctx, inputUpdateDTO)
@@ -100,6 +200,43 @@ func (u *todoUseCase) UpdateTodo(ctx context.Context, input *input.UpdateTodoInp
 	return output.NewTodoOutput(updated), nil
 }
 
+func (u *todoUseCase) ChangeTodoStatus(ctx context.Context, input *input.ChangeTodoStatusInput) (*output.TodoOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+	existing, err := u.todoRepo.FindByID(ctx, &dto.FindByIDInput{
+		ID:     input.ID,
+		UserID: input.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(todoTransitions[existing.Status], input.Status) {
+		return nil, apperrors.NewBusinessRuleError(
+			fmt.Sprintf("cannot change todo status from %s to %s", existing.Status, input.Status),
+			nil,
+		)
+	}
+
+	var completedAt *time.Time
+	if input.Status == domain.TodoStatusDone {
+		now := time.Now()
+		completedAt = &now
+	}
+	updated, err := u.todoRepo.UpdateStatus(ctx, &dto.UpdateTodoStatusInput{
+		ID:            input.ID,
+		UserID:        input.UserID,
+		CurrentStatus: existing.Status,
+		Status:        input.Status,
+		CompletedAt:   completedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return output.NewTodoOutput(updated), nil
+}
+
 func (u *todoUseCase) DeleteTodo(ctx context.Context, input *input.DeleteTodoInput) error {
 	if err := input.Validate(); err != nil {
 		return apperrors.NewValidationError("invalid input parameters", err)
@@ -119,4 +256,4 @@ func (u *todoUseCase) DeleteTodo(ctx context.Context, input *input.DeleteTodoInp
 		ID: input.ID,
 	}
 	return u.todoRepo.Delete(ctx, inputDeleteDTO)
-}
\ No newline at end of file
+}
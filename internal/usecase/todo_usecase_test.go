@@ -0,0 +1,162 @@
+package usecase_test
+
+import (
+	"context"
+	"go-boilerplate/internal/domain"
+	"go-boilerplate/internal/infrastructure/persistence/dto"
+	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/repository"
+	"go-boilerplate/internal/usecase"
+	"go-boilerplate/internal/usecase/input"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestChangeTodoStatusTransitions(t *testing.T) {
+	allowed := map[domain.TodoStatus][]domain.TodoStatus{
+		domain.TodoStatusOpen:       {domain.TodoStatusInProgress, domain.TodoStatusDone, domain.TodoStatusCancelled},
+		domain.TodoStatusInProgress: {domain.TodoStatusOpen, domain.TodoStatusDone, domain.TodoStatusCancelled},
+		domain.TodoStatusDone:       {domain.TodoStatusOpen},
+		domain.TodoStatusCancelled:  {domain.TodoStatusOpen},
+	}
+	statuses := []domain.TodoStatus{domain.TodoStatusOpen, domain.TodoStatusInProgress, domain.TodoStatusDone, domain.TodoStatusCancelled}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := false
+			for _, status := range allowed[from] {
+				want = want || status == to
+			}
+
+			t.Run(string(from)+"_to_"+string(to), func(t *testing.T) {
+				repo := newMemoryTodoRepository(from)
+				todoUseCase := usecase.NewTodoUseCase(repo)
+
+				updated, err := todoUseCase.ChangeTodoStatus(context.Background(), repo.changeInput(to))
+				if !want {
+					if !apperrors.Is(err, apperrors.BusinessRuleError) {
+						t.Fatalf("expected a business rule error, got %v", err)
+					}
+					if repo.updates != 0 || repo.todo.Status != from {
+						t.Fatalf("expected the todo to stay %s, got %s after %d updates", from, repo.todo.Status, repo.updates)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("expected the transition to be allowed, got %v", err)
+				}
+				if updated.Status != to {
+					t.Fatalf("expected status %s, got %s", to, updated.Status)
+				}
+				if repo.lastUpdate.CurrentStatus != from {
+					t.Fatalf("expected the update to be guarded by status %s, got %s", from, repo.lastUpdate.CurrentStatus)
+				}
+			})
+		}
+	}
+}
+
+func TestChangeTodoStatusCompletedAt(t *testing.T) {
+	tests := []struct {
+		name          string
+		from          domain.TodoStatus
+		to            domain.TodoStatus
+		wantCompleted bool
+	}{
+		{name: "open to done sets completed_at", from: domain.TodoStatusOpen, to: domain.TodoStatusDone, wantCompleted: true},
+		{name: "in_progress to done sets completed_at", from: domain.TodoStatusInProgress, to: domain.TodoStatusDone, wantCompleted: true},
+		{name: "done to open clears completed_at", from: domain.TodoStatusDone, to: domain.TodoStatusOpen},
+		{name: "open to cancelled leaves completed_at empty", from: domain.TodoStatusOpen, to: domain.TodoStatusCancelled},
+		{name: "open to in_progress leaves completed_at empty", from: domain.TodoStatusOpen, to: domain.TodoStatusInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryTodoRepository(tt.from)
+			if tt.from == domain.TodoStatusDone {
+				completedAt := time.Now().Add(-time.Hour)
+				repo.todo.CompletedAt = &completedAt
+			}
+			before := time.Now()
+
+			updated, err := usecase.NewTodoUseCase(repo).ChangeTodoStatus(context.Background(), repo.changeInput(tt.to))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantCompleted {
+				if updated.CompletedAt != nil {
+					t.Fatalf("expected completed_at to be empty, got %v", updated.CompletedAt)
+				}
+				return
+			}
+			if updated.CompletedAt == nil || updated.CompletedAt.Before(before) {
+				t.Fatalf("expected completed_at to be set to the current time, got %v", updated.CompletedAt)
+			}
+		})
+	}
+}
+
+func TestChangeTodoStatusRejectsConcurrentChange(t *testing.T) {
+	repo := newMemoryTodoRepository(domain.TodoStatusOpen)
+	// another request finishes the todo between the transition check and the update
+	repo.beforeUpdate = func() { repo.todo.Status = domain.TodoStatusDone }
+
+	_, err := usecase.NewTodoUseCase(repo).ChangeTodoStatus(context.Background(), repo.changeInput(domain.TodoStatusCancelled))
+	if !apperrors.Is(err, apperrors.BusinessRuleError) {
+		t.Fatalf("expected a business rule error, got %v", err)
+	}
+	if repo.todo.Status != domain.TodoStatusDone {
+		t.Fatalf("expected the concurrent change to be kept, got %s", repo.todo.Status)
+	}
+}
+
+// memoryTodoRepository は 1 件の todo をメモリ上に保持し、ステータスの変更に使うメソッドだけを実装します。
+// UpdateStatus は本物と同じく、ステータスが CurrentStatus のままの場合だけ更新します
+type memoryTodoRepository struct {
+	repository.TodoRepository
+	todo         dto.TodoOutput
+	updates      int
+	lastUpdate   dto.UpdateTodoStatusInput
+	beforeUpdate func()
+}
+
+func newMemoryTodoRepository(status domain.TodoStatus) *memoryTodoRepository {
+	return &memoryTodoRepository{todo: dto.TodoOutput{
+		ID:       uuid.New(),
+		UserID:   uuid.New(),
+		Title:    "todo",
+		Status:   status,
+		Priority: domain.TodoPriorityMedium,
+	}}
+}
+
+func (r *memoryTodoRepository) changeInput(status domain.TodoStatus) *input.ChangeTodoStatusInput {
+	return &input.ChangeTodoStatusInput{ID: r.todo.ID, UserID: r.todo.UserID, Status: status}
+}
+
+func (r *memoryTodoRepository) FindByID(ctx context.Context, input *dto.FindByIDInput) (*dto.TodoOutput, error) {
+	if input.ID != r.todo.ID || input.UserID != r.todo.UserID {
+		return nil, apperrors.NewNotFoundError("todo not found", nil)
+	}
+	todo := r.todo
+	return &todo, nil
+}
+
+func (r *memoryTodoRepository) UpdateStatus(ctx context.Context, input *dto.UpdateTodoStatusInput) (*dto.TodoOutput, error) {
+	if r.beforeUpdate != nil {
+		r.beforeUpdate()
+	}
+	r.updates++
+	r.lastUpdate = *input
+	if input.ID != r.todo.ID || input.UserID != r.todo.UserID {
+		return nil, apperrors.NewNotFoundError("todo not found", nil)
+	}
+	if r.todo.Status != input.CurrentStatus {
+		return nil, apperrors.NewBusinessRuleError("todo status was changed by another request", nil)
+	}
+	r.todo.Status = input.Status
+	r.todo.CompletedAt = input.CompletedAt
+	return r.FindByID(ctx, &dto.FindByIDInput{ID: input.ID, UserID: input.UserID})
+}
@@ -2,35 +2,226 @@ package usecase
 
 import (
 	"context"
+	"go-boilerplate/internal/domain"
 	"go-boilerplate/internal/infrastructure/persistence/dto"
+	"go-boilerplate/internal/pkg/auth"
 	apperrors "go-boilerplate/internal/pkg/errors"
+	"go-boilerplate/internal/pkg/mailer"
 	"go-boilerplate/internal/repository"
 	"go-boilerplate/internal/usecase/input"
 	"go-boilerplate/internal/usecase/output"
+	"log"
+
+	"github.com/google/uuid"
 )
 
 type UserUseCase interface {
-	GetUserByEmail(ctx context.Context, input *input.GetUserByEmailInput) (*output.UserOutput, error)
+	GetUser(ctx context.Context, input *input.GetUserInput) (*output.UserOutput, error)
+	UpdateProfile(ctx context.Context, input *input.UpdateProfileInput) (*output.UserOutput, error)
+	ChangePassword(ctx context.Context, input *input.ChangePasswordInput) error
+	DeleteAccount(ctx context.Context, input *input.DeleteAccountInput) error
 }
 
 type useUseCase struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	revokedTokenRepo repository.RevokedTokenRepository
+	userTokenRepo    repository.UserTokenRepository
+	passwordHasher   auth.PasswordHasher
+	passwordPolicy   *auth.PasswordPolicy
+	auditLogger      AuditLogger
+	mailer           mailer.Mailer
 }
 
-func NewUserUseCase(userRepo repository.UserRepository) UserUseCase {
-	return &useUseCase{userRepo: userRepo}
+func NewUserUseCase(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	revokedTokenRepo repository.RevokedTokenRepository,
+	userTokenRepo repository.UserTokenRepository,
+	passwordHasher auth.PasswordHasher,
+	passwordPolicy *auth.PasswordPolicy,
+	auditLogger AuditLogger,
+	mailer mailer.Mailer,
+) UserUseCase {
+	return &useUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		revokedTokenRepo: revokedTokenRepo,
+		userTokenRepo:    userTokenRepo,
+		passwordHasher:   passwordHasher,
+		passwordPolicy:   passwordPolicy,
+		auditLogger:      auditLogger,
+		mailer:           mailer,
+	}
 }
 
-func (u *useUseCase) GetUserByEmail(ctx context.Context, input *input.GetUserByEmailInput) (*output.UserOutput, error) {
+func (u *useUseCase) GetUser(ctx context.Context, input *input.GetUserInput) (*output.UserOutput, error) {
 	if err := input.Validate(); err != nil {
 		return nil, apperrors.NewValidationError("invalid input parameters", err)
 	}
-	user, err := u.userRepo.FindByEmail(ctx, &dto.FindUserByEmailInput{
-		Email: input.Email,
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{
+		ID: input.ID,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	return output.ConvertUserOutput(user), nil
-}
\ No newline at end of file
+}
+
+func (u *useUseCase) UpdateProfile(ctx context.Context, input *input.UpdateProfileInput) (*output.UserOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, apperrors.NewValidationError("invalid input parameters", err)
+	}
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{
+		ID: input.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updateDTO := &dto.UpdateUserInput{
+		ID:              user.ID,
+		Name:            user.Name,
+		Email:           user.Email,
+		Timezone:        user.Timezone,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+	}
+	if input.Name != nil {
+		updateDTO.Name = *input.Name
+	}
+	if input.Timezone != nil {
+		updateDTO.Timezone = *input.Timezone
+	}
+	emailChanged := input.Email != nil && *input.Email != user.Email
+	if emailChanged {
+		// the address receives password resets, so moving it needs the same proof as changing the password
+		if err := u.verifyCurrentPassword(ctx, user, input.CurrentPassword, domain.AuditActionEmailChange, input.IPAddress, input.UserAgent); err != nil {
+			return nil, err
+		}
+		// a new address has to be verified again
+		updateDTO.Email = *input.Email
+		updateDTO.EmailVerifiedAt = nil
+	}
+
+	updated, err := u.userRepo.Update(ctx, updateDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		recordAudit(ctx, u.auditLogger, &AuditEntry{
+			Action:    domain.AuditActionEmailChange,
+			Outcome:   domain.AuditOutcomeSuccess,
+			ActorID:   &user.ID,
+			TargetID:  &user.ID,
+			IPAddress: input.IPAddress,
+			UserAgent: input.UserAgent,
+			Metadata:  map[string]string{"old_email": user.Email, "new_email": updated.Email},
+		})
+		if err := sendVerificationEmail(ctx, u.userTokenRepo, u.mailer, updated); err != nil {
+			log.Printf("failed to send verification email: %v", err)
+		}
+	}
+
+	return output.ConvertUserOutput(updated), nil
+}
+
+func (u *useUseCase) ChangePassword(ctx context.Context, input *input.ChangePasswordInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{
+		ID: input.UserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := u.verifyCurrentPassword(ctx, user, input.CurrentPassword, domain.AuditActionPasswordChange, input.IPAddress, input.UserAgent); err != nil {
+		return err
+	}
+	if err := checkPasswordPolicy(u.passwordPolicy, input.NewPassword); err != nil {
+		return err
+	}
+
+	// hash password
+	hashedPassword, err := u.passwordHasher.Hash(input.NewPassword)
+	if err != nil {
+		return apperrors.NewInternalError("failed to hash password", err)
+	}
+	if err := u.userRepo.UpdatePassword(ctx, &dto.UpdateUserPasswordInput{
+		ID:       user.ID,
+		Password: hashedPassword,
+	}); err != nil {
+		return err
+	}
+
+	// sign out every other device but keep the current one
+	if err := revokeUserSessions(ctx, u.refreshTokenRepo, u.revokedTokenRepo, user.ID, input.SessionID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionPasswordChange,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+	return nil
+}
+
+func (u *useUseCase) DeleteAccount(ctx context.Context, input *input.DeleteAccountInput) error {
+	if err := input.Validate(); err != nil {
+		return apperrors.NewValidationError("invalid input parameters", err)
+	}
+	user, err := u.userRepo.FindByID(ctx, &dto.FindUserByIDInput{
+		ID: input.UserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := u.verifyCurrentPassword(ctx, user, input.CurrentPassword, domain.AuditActionAccountDelete, input.IPAddress, input.UserAgent); err != nil {
+		return err
+	}
+
+	if err := u.userRepo.SoftDelete(ctx, &dto.DeleteUserInput{ID: user.ID}); err != nil {
+		return err
+	}
+	if err := revokeUserSessions(ctx, u.refreshTokenRepo, u.revokedTokenRepo, user.ID, uuid.Nil); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, u.auditLogger, &AuditEntry{
+		Action:    domain.AuditActionAccountDelete,
+		Outcome:   domain.AuditOutcomeSuccess,
+		ActorID:   &user.ID,
+		TargetID:  &user.ID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+	return nil
+}
+
+// verifyCurrentPassword は本人確認のために現在のパスワードを検証し、誤っていれば action の失敗として記録します
+func (u *useUseCase) verifyCurrentPassword(ctx context.Context, user *dto.UserOutput, password string, action domain.AuditAction, ipAddress string, userAgent string) error {
+	if password == "" {
+		return apperrors.NewValidationError("current password is required", nil)
+	}
+	if _, err := u.passwordHasher.Verify(user.Password, password); err != nil {
+		recordAudit(ctx, u.auditLogger, &AuditEntry{
+			Action:    action,
+			Outcome:   domain.AuditOutcomeFailure,
+			ActorID:   &user.ID,
+			TargetID:  &user.ID,
+			IPAddress: ipAddress,
+			UserAgent: userAgent,
+			Metadata:  map[string]string{"reason": "invalid_current_password"},
+		})
+		return apperrors.NewValidationError("current password is incorrect", err)
+	}
+	return nil
+}